@@ -0,0 +1,119 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/consensus"
+	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/ripemd160"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/base58"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+var (
+	ErrInvalidAddress  = errors.New("invalid address")
+	ErrAddressChecksum = errors.New("address checksum mismatch")
+	ErrAddressVersion  = errors.New("address version belongs to another network")
+)
+
+// AddressCodec encodes hashes of public keys and scripts into base58
+// addresses: a version byte, the hash and a checksum of both of them.
+type AddressCodec struct {
+	PubKeyHashAddrID byte
+	ScriptHashAddrID byte
+	Checksum         func(payload []byte) []byte
+}
+
+// NewAddressCodec creates a codec for addresses of given network.
+func NewAddressCodec(p *params.ChainParams) AddressCodec {
+	codec := AddressCodec{
+		PubKeyHashAddrID: p.PubKeyHashAddrID,
+		ScriptHashAddrID: p.ScriptHashAddrID,
+		Checksum:         SHA256DChecksum,
+	}
+	if p.AddressChecksum == params.GROESTL_CHECKSUM {
+		codec.Checksum = GroestlChecksum
+	}
+	return codec
+}
+
+// ActiveAddressCodec creates a codec for addresses of the active network.
+func ActiveAddressCodec() AddressCodec {
+	return NewAddressCodec(params.Active())
+}
+
+// Encode creates an address of given version for the hash.
+func (c AddressCodec) Encode(version byte, hash []byte) []byte {
+	payload := append([]byte{version}, hash...)
+	return base58.Encode(append(payload, c.Checksum(payload)...))
+}
+
+// EncodePubKeyHash creates a pay-to-pubkey-hash address.
+func (c AddressCodec) EncodePubKeyHash(pubKeyHash []byte) []byte {
+	return c.Encode(c.PubKeyHashAddrID, pubKeyHash)
+}
+
+// EncodeScriptHash creates a pay-to-script-hash address.
+func (c AddressCodec) EncodeScriptHash(scriptHash []byte) []byte {
+	return c.Encode(c.ScriptHashAddrID, scriptHash)
+}
+
+// Decode checks the address and returns its version and hash.
+func (c AddressCodec) Decode(address string) (byte, []byte, error) {
+	data := base58.Decode([]byte(address))
+	if len(data) != 1+ripemd160.Size+ADDRESS_CHECKSUM_LEN {
+		return 0, nil, ErrInvalidAddress
+	}
+	payload := data[:len(data)-ADDRESS_CHECKSUM_LEN]
+	if !bytes.Equal(data[len(payload):], c.Checksum(payload)) {
+		return 0, nil, ErrAddressChecksum
+	}
+	version := payload[0]
+	if version != c.PubKeyHashAddrID && version != c.ScriptHashAddrID {
+		return 0, nil, ErrAddressVersion
+	}
+	return version, payload[1:], nil
+}
+
+// ConvertAddress decodes the address with the codec of one network and
+// encodes the same key or script hash with the codec of another one.
+func ConvertAddress(address string, from, to AddressCodec) ([]byte, error) {
+	version, hash, err := from.Decode(address)
+	if err != nil {
+		return nil, err
+	}
+	if version == from.ScriptHashAddrID {
+		return to.EncodeScriptHash(hash), nil
+	}
+	return to.EncodePubKeyHash(hash), nil
+}
+
+// SHA256DChecksum computes the checksum of Bitcoin addresses, which is
+// the first bytes of double SHA-256.
+func SHA256DChecksum(payload []byte) []byte {
+	firstSHA := sha256.Sum256(payload)
+	secondSHA := sha256.Sum256(firstSHA[:])
+	return secondSHA[:ADDRESS_CHECKSUM_LEN]
+}
+
+// GroestlChecksum computes the checksum of Groestlcoin addresses, which is
+// the first bytes of double Groestl-512.
+func GroestlChecksum(payload []byte) []byte {
+	return consensus.GroestlHash(payload)[:ADDRESS_CHECKSUM_LEN]
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+func TestAddressCodec_Groestlcoin(test *testing.T) {
+	codec := NewAddressCodec(&params.GroestlcoinParams)
+	hash := bytes.Repeat([]byte{0x5a}, 20)
+	data := []struct {
+		name    string
+		address []byte
+		version byte
+		prefix  byte
+	}{
+		{"pay-to-pubkey-hash", codec.EncodePubKeyHash(hash), 36, 'F'},
+		{"pay-to-script-hash", codec.EncodeScriptHash(hash), 5, '3'},
+	}
+	for _, d := range data {
+		if d.address[0] != d.prefix {
+			test.Errorf("wallet.TestAddressCodec_Groestlcoin, %s prefix:\nactual:\n%c\nexpected:\n%c", d.name, d.address[0], d.prefix)
+		}
+		version, actual, err := codec.Decode(string(d.address))
+		if err != nil || version != d.version || !bytes.Equal(actual, hash) {
+			test.Errorf("wallet.TestAddressCodec_Groestlcoin, %s:\nactual:\n%d %x %v\nexpected:\n%d %x <nil>", d.name, version, actual, err, d.version, hash)
+		}
+	}
+}
+
+func TestAddressCodec_Decode(test *testing.T) {
+	groestlcoin := NewAddressCodec(&params.GroestlcoinParams)
+	mainNet := NewAddressCodec(&params.MainNetParams)
+	hash := bytes.Repeat([]byte{0x01}, 20)
+
+	// Bitcoin style checksum with the Groestlcoin version byte.
+	sha256dAddress := AddressCodec{PubKeyHashAddrID: 36, Checksum: SHA256DChecksum}.EncodePubKeyHash(hash)
+	data := []struct {
+		name     string
+		codec    AddressCodec
+		address  string
+		expected error
+	}{
+		{"empty", mainNet, "", ErrInvalidAddress},
+		{"short hash", mainNet, string(mainNet.Encode(0x00, hash[:19])), ErrInvalidAddress},
+		{"another network", mainNet, string(NewAddressCodec(&params.TestNetParams).EncodePubKeyHash(hash)), ErrAddressVersion},
+		{"another checksum", groestlcoin, string(sha256dAddress), ErrAddressChecksum},
+		{"valid", groestlcoin, string(groestlcoin.EncodePubKeyHash(hash)), nil},
+	}
+	for _, d := range data {
+		_, _, actual := d.codec.Decode(d.address)
+		if actual != d.expected {
+			test.Errorf("wallet.TestAddressCodec_Decode, %s:\nactual:\n%v\nexpected:\n%v", d.name, actual, d.expected)
+		}
+	}
+}
+
+// The key paid by genesis blocks of Bitcoin and Groestlcoin, its Bitcoin
+// address is 1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa.
+const genesisPubKeyHex = "04678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61de" +
+	"b649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5f"
+
+// The genesis block header of Groestlcoin, the first bytes of its hash are 2390633b.
+const groestlcoinGenesisHeaderHex = "7000000000000000000000000000000000000000000000000000000000000000" +
+	"00000000bb2866aaca46c4428ad08b57bc9d1493abaf64724b6c3052a7c8f958df68e93ced3d2b53ffff0f1e835b0300"
+
+func TestConvertAddress_Groestlcoin(test *testing.T) {
+	pubKey, _ := hex.DecodeString(genesisPubKeyHex)
+	pubKeyHash := HashPubKey(pubKey)
+	if hex.EncodeToString(pubKeyHash) != "62e907b15cbf27d5425399ebf6f0fb50ebb88f18" {
+		test.Fatalf("wallet.TestConvertAddress_Groestlcoin, hash160:\nactual:\n%x", pubKeyHash)
+	}
+	bitcoin := string(NewAddressCodec(&params.MainNetParams).EncodePubKeyHash(pubKeyHash))
+	if bitcoin != "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" {
+		test.Fatalf("wallet.TestConvertAddress_Groestlcoin, bitcoin:\nactual:\n%s\nexpected:\n1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", bitcoin)
+	}
+
+	// The checksum is the hash Groestlcoin uses for blocks.
+	header, _ := hex.DecodeString(groestlcoinGenesisHeaderHex)
+	if checksum := GroestlChecksum(header); hex.EncodeToString(checksum) != "2390633b" {
+		test.Errorf("wallet.TestConvertAddress_Groestlcoin, checksum:\nactual:\n%x\nexpected:\n2390633b", checksum)
+	}
+
+	// Both parts of the Groestlcoin address are pinned above to real data.
+	groestlcoin := NewAddressCodec(&params.GroestlcoinParams)
+	converted, err := ConvertAddress(bitcoin, NewAddressCodec(&params.MainNetParams), groestlcoin)
+	if err != nil || string(converted) != "FeBhpvNkdtxC7K3LEVT8uqskzwC4mFYrhR" {
+		test.Errorf("wallet.TestConvertAddress_Groestlcoin:\nactual:\n%s, %v\nexpected:\nFeBhpvNkdtxC7K3LEVT8uqskzwC4mFYrhR, <nil>", converted, err)
+	}
+	version, hash, err := groestlcoin.Decode(string(converted))
+	if err != nil || version != 36 || !bytes.Equal(hash, pubKeyHash) {
+		test.Errorf("wallet.TestConvertAddress_Groestlcoin, decode:\nactual:\n%d %x %v\nexpected:\n36 %x <nil>", version, hash, err, pubKeyHash)
+	}
+}
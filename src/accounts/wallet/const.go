@@ -15,7 +15,5 @@
 
 package wallet
 
-const (
-	WALLLET_VERSION      = byte(0x00)
-	ADDRESS_CHECKSUM_LEN = 4
-)
+// Address version bytes depend on the network, see params.ChainParams.
+const ADDRESS_CHECKSUM_LEN = 4
@@ -16,7 +16,6 @@
 package wallet
 
 import (
-	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -25,7 +24,6 @@ import (
 
 	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/ripemd160"
 	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/secp256k1"
-	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/base58"
 )
 
 type Wallet struct {
@@ -39,13 +37,10 @@ func NewWallet() *Wallet {
 	return &wallet
 }
 
+// GetAddress returns the pay-to-pubkey-hash address of the wallet
+// on the active network.
 func (w Wallet) GetAddress() []byte {
-	pubKeyHash := HashPubKey(w.PublicKey)
-	versionedPayload := append([]byte{WALLLET_VERSION}, pubKeyHash...)
-	checksum := checksum(versionedPayload)
-	fullPayload := append(versionedPayload, checksum...)
-	address := base58.Encode(fullPayload)
-	return address
+	return ActiveAddressCodec().EncodePubKeyHash(HashPubKey(w.PublicKey))
 }
 
 func HashPubKey(pubKey []byte) []byte {
@@ -59,22 +54,14 @@ func HashPubKey(pubKey []byte) []byte {
 	return publicRIPEMD160
 }
 
+// ValidateAddress checks the checksum of given address and that it
+// belongs to the active network.
 func ValidateAddress(address string) bool {
-	pubKeyHash := base58.Decode([]byte(address))
-	actualChecksum := pubKeyHash[len(pubKeyHash)-ADDRESS_CHECKSUM_LEN:]
-	version := pubKeyHash[0]
-	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-ADDRESS_CHECKSUM_LEN]
-	targetChecksum := checksum(append([]byte{version}, pubKeyHash...))
-	return bytes.Compare(actualChecksum, targetChecksum) == 0
+	_, _, err := ActiveAddressCodec().Decode(address)
+	return err == nil
 }
 
-func checksum(payload []byte) []byte {
-	firstSHA := sha256.Sum256(payload)
-	secondSHA := sha256.Sum256(firstSHA[:])
-	return secondSHA[:ADDRESS_CHECKSUM_LEN]
-}
-
-func newKeyPair() (publicKey []byte, privateKey []byte) {
+func newKeyPair() (privateKey []byte, publicKey []byte) {
 	key, err := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
 	if err != nil {
 		panic(err)
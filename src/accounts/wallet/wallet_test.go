@@ -15,8 +15,32 @@
 
 package wallet
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
 
 func TestWallet(test *testing.T) {
 
 }
+
+func TestValidateAddress(test *testing.T) {
+	defer params.SetActive(params.Active())
+	params.SetActive(&params.MainNetParams)
+	address := string(NewWallet().GetAddress())
+	if !ValidateAddress(address) {
+		test.Errorf("wallet.TestValidateAddress, main network:\nactual:\n%v\nexpected:\n%v", false, true)
+	}
+	for _, invalid := range []string{"", "1", address[:len(address)-1] + "z"} {
+		if ValidateAddress(invalid) {
+			test.Errorf("wallet.TestValidateAddress, %q:\nactual:\n%v\nexpected:\n%v", invalid, true, false)
+		}
+	}
+
+	// Addresses of another network are rejected.
+	params.SetActive(&params.TestNetParams)
+	if ValidateAddress(address) {
+		test.Errorf("wallet.TestValidateAddress, test network:\nactual:\n%v\nexpected:\n%v", true, false)
+	}
+}
@@ -21,21 +21,30 @@ import (
 	"os"
 
 	"github.com/YuriyLisovskiy/blockchain-go/src/config"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
 )
 
 type CLI struct{}
 
 func (cli *CLI) printUsage() {
 	fmt.Println("Usage:")
-	fmt.Print("  config\n    -ip string\n\tNode ip address\n    -port\n\tNode id\n    -path.chain\n\tPath to block chain database\n    -path.wallets\n\tPath to wallets location\n    -default\n\tSet default config\n\n")
-	fmt.Print("  createblockchain\n    -address string\n\tThe address to send genesis block reward to\n\n")
+	fmt.Print("  checkblockfile\n    -file string\n\tPath to a blk*.dat file of Groestlcoin Core, checks proof of work and linkage of its headers\n\n")
+	fmt.Print("  config\n    -ip string\n\tNode ip address\n    -port\n\tNode id\n    -path.chain\n\tPath to block chain database\n    -path.wallets\n\tPath to wallets location\n    -txindex string\n\tMaintain the transaction index, true or false\n    -addrindex string\n\tMaintain the address index, true or false\n    -maxtimedrift\n\tSeconds a block's timestamp may be ahead of network time\n    -network string\n\tNetwork to join: mainnet, testnet or regtest\n    -minerthreads\n\tNumber of mining threads, 0 uses all CPUs\n    -default\n\tSet default config\n\n")
+	fmt.Print("  convertaddress\n    -address string\n\tThe address to convert\n    -network string\n\tNetwork to convert to: mainnet, testnet, regtest or groestlcoin\n\n")
+	fmt.Print("  createblockchain\n\tCreates a database with the genesis block of the configured network\n\n")
 	fmt.Print("  createwallet\n\tGenerates a new key-pair and saves it into the wallet file\n\n")
+	fmt.Print("  generate N [address]\n\tMines N blocks immediately paying to the address or a new one, the node must be stopped\n\n")
+	fmt.Print("  getaddresshistory\n    -address string\n\tThe address to list transactions for\n\n")
 	fmt.Print("  getbalance\n    -address string\n\tThe address to get balance for\n\n")
+	fmt.Print("  getrawtransaction\n    -txid string\n\tHash of the transaction\n    -verbose\n\tPrint the transaction as json\n\n")
+	fmt.Print("  gettxoutsetinfo\n\tPrints statistics of the UTXO set and the issued supply\n\n")
 	fmt.Print("  listaddresses\n\tLists all addresses from the wallet file\n\n")
 	fmt.Print("  printchain\n\tPrint all the blocks of the blockchain\n\n")
 	fmt.Print("  reindexutxo\n\tRebuilds the UTXO set\n\n")
-	fmt.Print("  send\n    -from string\n\tSource wallet address\n    -to string\n\tDestination wallet address\n    -amount int\n\tAmount to send\n    -mine\n\tMine on the same node\n\n")
+	fmt.Print("  reindextxindex\n\tRebuilds the transaction index\n\n")
+	fmt.Print("  send\n    -from string\n\tSource wallet address\n    -to string\n\tDestination wallet address\n    -amount string\n\tAmount to send, e.g. 1.25\n    -fee string\n\tFee per byte\n    -mine\n\tMine on the same node\n\n")
 	fmt.Print("  startnode\n    -miner string\n\tStart a node with ID specified in NODE_ID env. var. -miner enables mining\n\n")
 }
 
@@ -51,36 +60,63 @@ func (cli *CLI) Run() {
 
 	getBalanceAddress := getBalanceCmd.String("address", "", "The address to get balance for")
 
+	checkBlockFilePath := checkBlockFileCmd.String("file", "", "Path to a blk*.dat file of Groestlcoin Core")
+
 	configIp := configCmd.String("ip", "", "Node ip address")
 	configPort := configCmd.Int("port", -1, "Node id")
 	configChainPath := configCmd.String("path.chain", "", "Path to block chain database")
 	configWalletsPath := configCmd.String("path.wallets", "", "Path to wallets location")
+	configTxIndex := configCmd.String("txindex", "", "Maintain the transaction index, true or false")
+	configAddrIndex := configCmd.String("addrindex", "", "Maintain the address index, true or false")
+	configMaxTimeDrift := configCmd.Int64("maxtimedrift", -1, "Seconds a block's timestamp may be ahead of network time")
+	configNetwork := configCmd.String("network", "", "Network to join: mainnet, testnet or regtest")
+	configMinerThreads := configCmd.Int("minerthreads", -1, "Number of mining threads, 0 uses all CPUs")
 	configDefault := configCmd.Bool("default", false, "Set default config")
 
-	createBlockChainAddress := createBlockChainCmd.String("address", "", "The address to send genesis block reward to")
+	convertAddressAddress := convertAddressCmd.String("address", "", "The address to convert")
+	convertAddressNetwork := convertAddressCmd.String("network", "", "Network to convert to: mainnet, testnet, regtest or groestlcoin")
+
+	getAddrHistoryAddress := getAddrHistoryCmd.String("address", "", "The address to list transactions for")
+
+	getRawTxId := getRawTxCmd.String("txid", "", "Hash of the transaction")
+	getRawTxVerbose := getRawTxCmd.Bool("verbose", false, "Print the transaction as json")
 
 	sendFrom := sendCmd.String("from", "", "Source wallet address")
 	sendTo := sendCmd.String("to", "", "Destination wallet address")
-	sendAmount := sendCmd.Float64("amount", 0, "Amount to send")
-	sendFee := sendCmd.Float64("fee", vars.MIN_FEE_PER_BYTE, "Mine immediately on the same node")
+	sendAmount := sendCmd.String("amount", "", "Amount to send")
+	sendFee := sendCmd.String("fee", money.Amount(vars.MIN_FEE_PER_BYTE).String(), "Fee per byte")
 
 	startNodeMiner := startNodeCmd.String("mine", "", "Enable mining mode")
 
 	switch os.Args[1] {
-	case "balance":
+	case "balance", "getbalance":
 		checkError(getBalanceCmd.Parse(os.Args[2:]))
+	case "checkblockfile":
+		checkError(checkBlockFileCmd.Parse(os.Args[2:]))
 	case "config":
 		checkError(configCmd.Parse(os.Args[2:]))
+	case "convertaddress":
+		checkError(convertAddressCmd.Parse(os.Args[2:]))
 	case "createblockchain":
 		checkError(createBlockChainCmd.Parse(os.Args[2:]))
 	case "createwallet":
 		checkError(createWalletCmd.Parse(os.Args[2:]))
+	case "generate":
+		checkError(generateCmd.Parse(os.Args[2:]))
+	case "getaddresshistory":
+		checkError(getAddrHistoryCmd.Parse(os.Args[2:]))
+	case "getrawtransaction":
+		checkError(getRawTxCmd.Parse(os.Args[2:]))
+	case "gettxoutsetinfo":
+		checkError(getTxOutSetInfoCmd.Parse(os.Args[2:]))
 	case "listaddresses":
 		checkError(listAddressesCmd.Parse(os.Args[2:]))
 	case "printchain":
 		checkError(printChainCmd.Parse(os.Args[2:]))
 	case "reindexutxo":
 		checkError(reindexUTXOCmd.Parse(os.Args[2:]))
+	case "reindextxindex":
+		checkError(reindexTxIndexCmd.Parse(os.Args[2:]))
 	case "send":
 		checkError(sendCmd.Parse(os.Args[2:]))
 	case "startnode":
@@ -93,8 +129,26 @@ func (cli *CLI) Run() {
 		if *configDefault {
 			cli.setDefaultConfig()
 		} else {
-			cli.setConfig(*configIp, *configPort, *configChainPath, *configWalletsPath)
+			cli.setConfig(*configIp, *configPort, *configChainPath, *configWalletsPath, *configTxIndex, *configAddrIndex, *configMaxTimeDrift, *configNetwork, *configMinerThreads)
+		}
+	}
+
+	// Block files and addresses are read without the node's configuration.
+	if checkBlockFileCmd.Parsed() {
+		if *checkBlockFilePath == "" {
+			checkBlockFileCmd.Usage()
+			os.Exit(1)
+		}
+		checkError(cli.checkBlockFile(*checkBlockFilePath))
+		return
+	}
+	if convertAddressCmd.Parsed() {
+		if *convertAddressAddress == "" || *convertAddressNetwork == "" {
+			convertAddressCmd.Usage()
+			os.Exit(1)
 		}
+		checkError(cli.convertAddress(*convertAddressAddress, *convertAddressNetwork))
+		return
 	}
 	if !config.Exists() {
 		log.Println(ErrConfigNotFound)
@@ -102,6 +156,9 @@ func (cli *CLI) Run() {
 	}
 	cfg, err := config.LoadConfig()
 	checkError(err)
+	network, err := params.ByName(cfg.Network)
+	checkError(err)
+	params.SetActive(network)
 	if getBalanceCmd.Parsed() {
 		if *getBalanceAddress == "" {
 			getBalanceCmd.Usage()
@@ -110,15 +167,31 @@ func (cli *CLI) Run() {
 		checkError(cli.getBalance(*getBalanceAddress, cfg))
 	}
 	if createBlockChainCmd.Parsed() {
-		if *createBlockChainAddress == "" {
-			createBlockChainCmd.Usage()
-			os.Exit(1)
-		}
-		checkError(cli.createBlockChain(*createBlockChainAddress, cfg))
+		checkError(cli.createBlockChain(cfg))
 	}
 	if createWalletCmd.Parsed() {
 		cli.createWallet(cfg)
 	}
+	if generateCmd.Parsed() {
+		checkError(cli.generate(generateCmd.Args(), cfg))
+	}
+	if getAddrHistoryCmd.Parsed() {
+		if *getAddrHistoryAddress == "" {
+			getAddrHistoryCmd.Usage()
+			os.Exit(1)
+		}
+		checkError(cli.getAddressHistory(*getAddrHistoryAddress, cfg))
+	}
+	if getRawTxCmd.Parsed() {
+		if *getRawTxId == "" {
+			getRawTxCmd.Usage()
+			os.Exit(1)
+		}
+		checkError(cli.getRawTransaction(*getRawTxId, *getRawTxVerbose, cfg))
+	}
+	if getTxOutSetInfoCmd.Parsed() {
+		checkError(cli.getTxOutSetInfo(cfg))
+	}
 	if listAddressesCmd.Parsed() {
 		checkError(cli.listAddresses(cfg))
 	}
@@ -128,8 +201,11 @@ func (cli *CLI) Run() {
 	if reindexUTXOCmd.Parsed() {
 		cli.reindexUTXO(cfg)
 	}
+	if reindexTxIndexCmd.Parsed() {
+		checkError(cli.reindexTxIndex(cfg))
+	}
 	if sendCmd.Parsed() {
-		if *sendFrom == "" || *sendTo == "" || *sendAmount <= 0 {
+		if *sendFrom == "" || *sendTo == "" || *sendAmount == "" {
 			sendCmd.Usage()
 			os.Exit(1)
 		}
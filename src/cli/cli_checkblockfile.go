@@ -0,0 +1,65 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/groestlcoin"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+func (cli *CLI) checkBlockFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	p := &params.GroestlcoinParams
+	reader := groestlcoin.NewBlockFileReader(file, p.Net)
+	var headers []types.BlockHeader
+	txCount := 0
+	for {
+		block, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.New(fmt.Sprintf("ERROR: Block %d of '%s' can not be decoded: %s", len(headers), path, err))
+		}
+		headers = append(headers, block.Header)
+		txCount += len(block.Transactions)
+	}
+	chain, stale := groestlcoin.BestHeaderChain(headers, p)
+	fmt.Printf("Blocks: %d, transactions: %d\n", len(headers), txCount)
+	fmt.Printf("Headers in chain: %d, not connected: %d\n", len(chain), stale)
+	if len(chain) == 0 {
+		return nil
+	}
+	fmt.Printf("First HASH: %x\n", groestlcoin.ReverseHash(groestlcoin.HeaderHash(chain[0], p)))
+	fmt.Printf("Last HASH: %x\n", groestlcoin.ReverseHash(groestlcoin.HeaderHash(chain[len(chain)-1], p)))
+	index, err := groestlcoin.CheckHeaderChain(chain, p)
+	if err != nil {
+		hash := groestlcoin.ReverseHash(groestlcoin.HeaderHash(chain[index], p))
+		return errors.New(fmt.Sprintf("ERROR: Header %d (%x) is invalid: %s", index, hash, err))
+	}
+	fmt.Println("Header chain is valid")
+	return nil
+}
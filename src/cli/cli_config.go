@@ -15,9 +15,14 @@
 
 package cli
 
-import "github.com/YuriyLisovskiy/blockchain-go/src/config"
+import (
+	"strconv"
 
-func (cli *CLI) setConfig(ip string, port int, chainPath, walletsPath string) error {
+	"github.com/YuriyLisovskiy/blockchain-go/src/config"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+func (cli *CLI) setConfig(ip string, port int, chainPath, walletsPath, txIndex, addrIndex string, maxTimeDrift int64, network string, minerThreads int) error {
 	cfg := config.Config{}
 	var err error
 	if config.Exists() {
@@ -26,6 +31,19 @@ func (cli *CLI) setConfig(ip string, port int, chainPath, walletsPath string) er
 			return err
 		}
 	}
+
+	// Joining another network moves the node to its default port,
+	// unless a port is given explicitly.
+	if network != "" {
+		p, err := params.ByName(network)
+		if err != nil {
+			return err
+		}
+		cfg = cfg.SetNetwork(p.Name)
+		if port == -1 {
+			port = p.DefaultPort
+		}
+	}
 	if ip != "" {
 		cfg = cfg.SetIp(ip)
 	}
@@ -38,6 +56,26 @@ func (cli *CLI) setConfig(ip string, port int, chainPath, walletsPath string) er
 	if walletsPath != "" {
 		cfg = cfg.SetWalletsPath(walletsPath)
 	}
+	if txIndex != "" {
+		enabled, err := strconv.ParseBool(txIndex)
+		if err != nil {
+			return err
+		}
+		cfg = cfg.SetTxIndex(enabled)
+	}
+	if addrIndex != "" {
+		enabled, err := strconv.ParseBool(addrIndex)
+		if err != nil {
+			return err
+		}
+		cfg = cfg.SetAddrIndex(enabled)
+	}
+	if maxTimeDrift != -1 {
+		cfg = cfg.SetMaxTimeDrift(maxTimeDrift)
+	}
+	if minerThreads != -1 {
+		cfg = cfg.SetMinerThreads(minerThreads)
+	}
 	return cfg.Save()
 }
 
@@ -0,0 +1,54 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+// addressNetworks are networks whose addresses can be converted, including
+// Groestlcoin, which the node can not join.
+var addressNetworks = []*params.ChainParams{
+	&params.MainNetParams,
+	&params.TestNetParams,
+	&params.RegTestParams,
+	&params.GroestlcoinParams,
+}
+
+func (cli *CLI) convertAddress(address, network string) error {
+	var to *params.ChainParams
+	for _, p := range addressNetworks {
+		if p.Name == network {
+			to = p
+		}
+	}
+	if to == nil {
+		return errors.New(fmt.Sprintf("ERROR: Network '%s' is not known", network))
+	}
+	for _, from := range addressNetworks {
+		converted, err := wallet.ConvertAddress(address, wallet.NewAddressCodec(from), wallet.NewAddressCodec(to))
+		if err == nil {
+			fmt.Printf("%s address: %s\n", from.Name, address)
+			fmt.Printf("%s address: %s\n", to.Name, converted)
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("ERROR: Address '%s' is not valid", address))
+}
@@ -16,19 +16,14 @@
 package cli
 
 import (
-	"errors"
 	"fmt"
 
-	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
 	"github.com/YuriyLisovskiy/blockchain-go/src/config"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core"
 )
 
-func (cli *CLI) createBlockChain(address string, cfg config.Config) error {
-	if !wallet.ValidateAddress(address) {
-		return errors.New(fmt.Sprintf("ERROR: Address '%s' is not valid", address))
-	}
-	bc := core.CreateBlockChain(address, cfg)
+func (cli *CLI) createBlockChain(cfg config.Config) error {
+	bc := core.CreateBlockChain(cfg)
 	UTXOSet := core.UTXOSet{BlockChain: bc}
 	UTXOSet.Reindex()
 	bc.CloseDB(true)
@@ -0,0 +1,58 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/config"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core"
+)
+
+// generate mines blocks immediately, the reward goes to a new wallet
+// address if none is given. The node must not be running.
+func (cli *CLI) generate(args []string, cfg config.Config) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("ERROR: Usage: generate N [address]")
+	}
+	count, err := strconv.Atoi(args[0])
+	if err != nil || count < 1 {
+		return errors.New(fmt.Sprintf("ERROR: Invalid number of blocks '%s'", args[0]))
+	}
+	var address string
+	if len(args) == 2 {
+		address = args[1]
+		if !wallet.ValidateAddress(address) {
+			return errors.New(fmt.Sprintf("ERROR: Address '%s' is not valid", address))
+		}
+	} else {
+		wallets, _ := wallet.NewWallets(cfg)
+		address = wallets.CreateWallet()
+		wallets.SaveToFile(cfg)
+		fmt.Printf("Your new address: %s\n", address)
+	}
+	bc := core.NewBlockChain(cfg)
+	defer bc.CloseDB(true)
+	hashes, err := bc.GenerateBlocks(context.Background(), count, address)
+	for _, hash := range hashes {
+		fmt.Printf("%x\n", hash)
+	}
+	return err
+}
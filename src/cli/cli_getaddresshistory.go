@@ -0,0 +1,46 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/config"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core"
+)
+
+func (cli *CLI) getAddressHistory(address string, cfg config.Config) error {
+	_, pubKeyHash, err := wallet.ActiveAddressCodec().Decode(address)
+	if err != nil {
+		return errors.New(fmt.Sprintf("ERROR: Address '%s' is not valid", address))
+	}
+	bc := core.NewBlockChain(cfg)
+	defer bc.CloseDB(false)
+	history, err := bc.GetAddressHistory(pubKeyHash)
+	if err == core.ErrAddrIndexDisabled {
+		return errors.New("ERROR: Address index is disabled, enable it with 'config -addrindex true'")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transactions of '%s': %d\n", address, len(history))
+	for _, item := range history {
+		fmt.Printf("Height: %d, Tx HASH: %x\n", item.Height, item.TxHash)
+	}
+	return nil
+}
@@ -22,23 +22,26 @@ import (
 	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
 	"github.com/YuriyLisovskiy/blockchain-go/src/config"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core"
-	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/base58"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
 )
 
 func (cli *CLI) getBalance(address string, cfg config.Config) error {
-	if !wallet.ValidateAddress(address) {
+	_, pubKeyHash, err := wallet.ActiveAddressCodec().Decode(address)
+	if err != nil {
 		return errors.New(fmt.Sprintf("ERROR: Address '%s' is not valid", address))
 	}
 	bc := core.NewBlockChain(cfg)
 	UTXOSet := core.UTXOSet{BlockChain: bc}
-	balance := 0.0
-	pubKeyHash := base58.Decode([]byte(address))
-	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-4]
+	balance := money.Amount(0)
 	UTXOs := UTXOSet.FindUTXO(pubKeyHash)
 	for _, out := range UTXOs {
-		balance += out.Value
+		balance, err = money.Sum(balance, out.Value)
+		if err != nil {
+			bc.CloseDB(true)
+			return errors.New(fmt.Sprintf("ERROR: Balance of '%s' is out of range: %s", address, err))
+		}
 	}
 	bc.CloseDB(true)
-	fmt.Printf("Balance of '%s': %.6f\n", address, balance)
+	fmt.Printf("Balance of '%s': %s\n", address, balance)
 	return nil
 }
@@ -0,0 +1,53 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/config"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core"
+)
+
+func (cli *CLI) getRawTransaction(txId string, verbose bool, cfg config.Config) error {
+	txHash, err := hex.DecodeString(txId)
+	if err != nil {
+		return errors.New(fmt.Sprintf("ERROR: Transaction hash '%s' is not valid", txId))
+	}
+	bc := core.NewBlockChain(cfg)
+	defer bc.CloseDB(false)
+	tx, blockHash, err := bc.GetRawTransaction(txHash)
+	if err == core.ErrTxIndexDisabled {
+		return errors.New("ERROR: Transaction index is disabled, enable it with 'config -txindex true'")
+	}
+	if err != nil {
+		return err
+	}
+	if !verbose {
+		fmt.Printf("%x\n", tx.Serialize())
+		return nil
+	}
+	data, err := json.MarshalIndent(tx, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	fmt.Printf("Block HASH: %x\n", blockHash)
+	return nil
+}
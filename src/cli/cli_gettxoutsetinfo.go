@@ -0,0 +1,39 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/config"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core"
+)
+
+func (cli *CLI) getTxOutSetInfo(cfg config.Config) error {
+	bc := core.NewBlockChain(cfg)
+	defer bc.CloseDB(false)
+	info, err := core.UTXOSet{BlockChain: bc}.GetTxOutSetInfo()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Height: %d\n", info.Height)
+	fmt.Printf("Best block HASH: %x\n", info.BestBlock)
+	fmt.Printf("Transactions: %d\n", info.Transactions)
+	fmt.Printf("Unspent outputs: %d\n", info.Outputs)
+	fmt.Printf("Total amount: %s\n", info.TotalAmount)
+	fmt.Printf("Total issued: %s\n", info.TotalSubsidy)
+	return nil
+}
@@ -23,11 +23,20 @@ import (
 	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
 	"github.com/YuriyLisovskiy/blockchain-go/src/config"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
 	"github.com/YuriyLisovskiy/blockchain-go/src/p2p/protocol"
 	"github.com/YuriyLisovskiy/blockchain-go/src/p2p/static"
 )
 
-func (cli *CLI) send(from, to string, amount, fee float64, cfg config.Config) error {
+func (cli *CLI) send(from, to, amountStr, feeStr string, cfg config.Config) error {
+	amount, err := money.ParseAmount(amountStr)
+	if err != nil || amount == 0 {
+		return errors.New(fmt.Sprintf("ERROR: Amount '%s' is not valid", amountStr))
+	}
+	fee, err := money.ParseAmount(feeStr)
+	if err != nil {
+		return errors.New(fmt.Sprintf("ERROR: Fee '%s' is not valid", feeStr))
+	}
 	if !wallet.ValidateAddress(from) {
 		return errors.New("ERROR: Sender address is not valid")
 	}
@@ -49,7 +58,10 @@ func (cli *CLI) send(from, to string, amount, fee float64, cfg config.Config) er
 //	newBlock := bc.MineBlock(from, []*blockchain.Transaction{tx})
 //	UTXOSet.Update(newBlock)
 
-	fmt.Printf("\n\nTX VERIFIED: %t\n\n", utxoSet.BlockChain.VerifyTransaction(tx))
+	err = utxoSet.BlockChain.VerifyTransaction(tx)
+	if err != nil {
+		return errors.New(fmt.Sprintf("ERROR: Transaction is not valid: %s", err))
+	}
 
 	data, err := json.MarshalIndent(tx, "", "  ")
 	if err != nil {
@@ -57,6 +69,7 @@ func (cli *CLI) send(from, to string, amount, fee float64, cfg config.Config) er
 	}
 	fmt.Println(string(data))
 
+	static.AddSeedNodes()
 	proto := protocol.Protocol{
 		Config: &protocol.Configuration{
 			Nodes: &static.KnownNodes,
@@ -22,6 +22,7 @@ import (
 	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
 	"github.com/YuriyLisovskiy/blockchain-go/src/config"
 	"github.com/YuriyLisovskiy/blockchain-go/src/p2p"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
 )
 
 func (cli *CLI) startNode(minerAddress string) error {
@@ -34,6 +35,9 @@ func (cli *CLI) startNode(minerAddress string) error {
 	}
 	fmt.Printf("Starting node %d\n", cfg.Port)
 	if len(minerAddress) > 0 {
+		if params.Active().MineBlocksOnDemand {
+			return errors.New(fmt.Sprintf("mining is disabled on %s, use generate", params.Active().Name))
+		}
 		if wallet.ValidateAddress(minerAddress) {
 			fmt.Println("Mining is on. Address to receive rewards: ", minerAddress)
 		} else {
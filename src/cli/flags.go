@@ -19,12 +19,19 @@ import "flag"
 
 var (
 	getBalanceCmd       = flag.NewFlagSet("balance", flag.ExitOnError)
+	checkBlockFileCmd   = flag.NewFlagSet("checkblockfile", flag.ExitOnError)
 	configCmd           = flag.NewFlagSet("config", flag.ExitOnError)
+	convertAddressCmd   = flag.NewFlagSet("convertaddress", flag.ExitOnError)
 	createBlockChainCmd = flag.NewFlagSet("createblockchain", flag.ExitOnError)
 	createWalletCmd     = flag.NewFlagSet("createwallet", flag.ExitOnError)
+	generateCmd         = flag.NewFlagSet("generate", flag.ExitOnError)
+	getAddrHistoryCmd   = flag.NewFlagSet("getaddresshistory", flag.ExitOnError)
+	getRawTxCmd         = flag.NewFlagSet("getrawtransaction", flag.ExitOnError)
+	getTxOutSetInfoCmd  = flag.NewFlagSet("gettxoutsetinfo", flag.ExitOnError)
 	listAddressesCmd    = flag.NewFlagSet("listaddresses", flag.ExitOnError)
 	printChainCmd       = flag.NewFlagSet("printchain", flag.ExitOnError)
 	reindexUTXOCmd      = flag.NewFlagSet("reindexutxo", flag.ExitOnError)
+	reindexTxIndexCmd   = flag.NewFlagSet("reindextxindex", flag.ExitOnError)
 	sendCmd             = flag.NewFlagSet("send", flag.ExitOnError)
 	startNodeCmd        = flag.NewFlagSet("startnode", flag.ExitOnError)
 )
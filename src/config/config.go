@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
 	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
 )
 
@@ -40,6 +41,20 @@ type Config struct {
 	Port        int    `json:"port"`
 	ChainPath   string `json:"chain_path"`
 	WalletsPath string `json:"wallets_path"`
+	TxIndex     bool   `json:"txindex"`
+	AddrIndex   bool   `json:"addrindex"`
+
+	// MaxTimeDrift is how many seconds a block's timestamp may be ahead
+	// of network-adjusted time, zero means the default value.
+	MaxTimeDrift int64 `json:"max_time_drift"`
+
+	// Network is the name of the network the node joins,
+	// empty means the main network.
+	Network string `json:"network"`
+
+	// MinerThreads is the number of goroutines used for mining,
+	// zero means the number of CPUs.
+	MinerThreads int `json:"miner_threads"`
 }
 
 // Default returns default node configuration.
@@ -66,7 +81,8 @@ func Default() (Config, error) {
 
 	// setup data
 	cfg.Ip = ip
-	cfg.Port = 8000
+	cfg.Network = params.MainNetParams.Name
+	cfg.Port = params.MainNetParams.DefaultPort
 	cfg.ChainPath = absPath + "/data/" + fmt.Sprintf(utils.DBFile, cfg.Port)
 	cfg.WalletsPath = absPath + "/data/" + fmt.Sprintf(utils.WalletFile, cfg.Port)
 
@@ -103,6 +119,36 @@ func (cfg Config) SetWalletsPath(path string) Config {
 	return cfg
 }
 
+// SetTxIndex enables or disables the transaction index.
+func (cfg Config) SetTxIndex(enabled bool) Config {
+	cfg.TxIndex = enabled
+	return cfg
+}
+
+// SetAddrIndex enables or disables the address index.
+func (cfg Config) SetAddrIndex(enabled bool) Config {
+	cfg.AddrIndex = enabled
+	return cfg
+}
+
+// SetMaxTimeDrift sets how many seconds a block's timestamp may be ahead of network-adjusted time.
+func (cfg Config) SetMaxTimeDrift(seconds int64) Config {
+	cfg.MaxTimeDrift = seconds
+	return cfg
+}
+
+// SetNetwork sets the name of the network the node operates on.
+func (cfg Config) SetNetwork(name string) Config {
+	cfg.Network = name
+	return cfg
+}
+
+// SetMinerThreads sets how many goroutines search for a block nonce.
+func (cfg Config) SetMinerThreads(threads int) Config {
+	cfg.MinerThreads = threads
+	return cfg
+}
+
 // Exists checks if configuration file exists on disk.
 func Exists() bool {
 	_, err := os.Stat(configLocation)
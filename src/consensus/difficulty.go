@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package consensus
+
+import "math/big"
+
+// CompactToBig converts a compact representation of a target ("nBits")
+// to a big integer. The compact form is a 3-byte mantissa with a sign bit
+// and a 1-byte base 256 exponent.
+func CompactToBig(compact uint32) *big.Int {
+	mantissa := compact & 0x007fffff
+	isNegative := compact&0x00800000 != 0
+	exponent := uint(compact >> 24)
+	var bn *big.Int
+	if exponent <= 3 {
+		mantissa >>= 8 * (3 - exponent)
+		bn = big.NewInt(int64(mantissa))
+	} else {
+		bn = big.NewInt(int64(mantissa))
+		bn.Lsh(bn, 8*(exponent-3))
+	}
+	if isNegative {
+		bn = bn.Neg(bn)
+	}
+	return bn
+}
+
+// BigToCompact converts a big integer to its compact representation.
+func BigToCompact(n *big.Int) uint32 {
+	if n.Sign() == 0 {
+		return 0
+	}
+	var mantissa uint32
+	abs := new(big.Int).Abs(n)
+	exponent := uint(len(abs.Bytes()))
+	if exponent <= 3 {
+		mantissa = uint32(abs.Uint64())
+		mantissa <<= 8 * (3 - exponent)
+	} else {
+		mantissa = uint32(new(big.Int).Rsh(abs, 8*(exponent-3)).Uint64())
+	}
+
+	// The sign bit is set, so move the mantissa one byte right.
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		exponent++
+	}
+	compact := uint32(exponent<<24) | mantissa
+	if n.Sign() < 0 {
+		compact |= 0x00800000
+	}
+	return compact
+}
+
+// BlockInfo holds data of a previous block required for retargeting.
+type BlockInfo struct {
+	Timestamp int64
+	Bits      uint32
+}
+
+// DarkGravityWave calculates the target of the next block as a moving
+// average of targets of pastBlocks previous blocks, adjusted by the ratio of
+// their actual timespan to the expected one. Previous blocks are given from
+// the newest one. If there are not enough of them, powLimit is returned.
+func DarkGravityWave(prevBlocks []BlockInfo, pastBlocks int, spacing int64, powLimit *big.Int) *big.Int {
+	if pastBlocks <= 0 || len(prevBlocks) < pastBlocks {
+		return new(big.Int).Set(powLimit)
+	}
+	pastTargetAvg := new(big.Int)
+	for i := 0; i < pastBlocks; i++ {
+		target := CompactToBig(prevBlocks[i].Bits)
+		if i == 0 {
+			pastTargetAvg.Set(target)
+		} else {
+			pastTargetAvg.Mul(pastTargetAvg, big.NewInt(int64(i+1)))
+			pastTargetAvg.Add(pastTargetAvg, target)
+			pastTargetAvg.Div(pastTargetAvg, big.NewInt(int64(i+2)))
+		}
+	}
+	actualTimespan := prevBlocks[0].Timestamp - prevBlocks[pastBlocks-1].Timestamp
+	targetTimespan := int64(pastBlocks) * spacing
+	if actualTimespan < targetTimespan/3 {
+		actualTimespan = targetTimespan / 3
+	}
+	if actualTimespan > targetTimespan*3 {
+		actualTimespan = targetTimespan * 3
+	}
+	newTarget := new(big.Int).Mul(pastTargetAvg, big.NewInt(actualTimespan))
+	newTarget.Div(newTarget, big.NewInt(targetTimespan))
+	if newTarget.Cmp(powLimit) > 0 {
+		newTarget.Set(powLimit)
+	}
+	return newTarget
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package consensus
+
+import (
+	"math/big"
+	"testing"
+)
+
+var Compact_Data = []struct {
+	compact uint32
+	hex     string
+}{
+	{compact: 0x1d00ffff, hex: "ffff0000000000000000000000000000000000000000000000000000"},
+	{compact: 0x1b0404cb, hex: "404cb000000000000000000000000000000000000000000000000"},
+	{compact: 0x1f00ffff, hex: "ffff00000000000000000000000000000000000000000000000000000000"},
+	{compact: 0x03123456, hex: "123456"},
+	{compact: 0x02008000, hex: "80"},
+	{compact: 0x0, hex: "0"},
+}
+
+func TestCompactToBig(test *testing.T) {
+	for i, data := range Compact_Data {
+		actual := CompactToBig(data.compact)
+		if actual.Text(16) != data.hex {
+			test.Errorf("consensus.TestCompactToBig[%d]:\nactual:\n%s\nexpected:\n%s", i, actual.Text(16), data.hex)
+		}
+	}
+}
+
+func TestBigToCompact(test *testing.T) {
+	for i, data := range Compact_Data {
+		n, _ := new(big.Int).SetString(data.hex, 16)
+		actual := BigToCompact(n)
+		if actual != data.compact {
+			test.Errorf("consensus.TestBigToCompact[%d]:\nactual:\n%x\nexpected:\n%x", i, actual, data.compact)
+		}
+	}
+}
+
+func newTestBlockInfos(count int, spacing int64, bits uint32) []BlockInfo {
+	var blocks []BlockInfo
+	for i := 0; i < count; i++ {
+		blocks = append(blocks, BlockInfo{Timestamp: int64(count-i) * spacing, Bits: bits})
+	}
+	return blocks
+}
+
+func TestDarkGravityWave(test *testing.T) {
+	powLimit := CompactToBig(0x1f00ffff)
+	bits := uint32(0x1e0fffff)
+	target := CompactToBig(bits)
+
+	// Not enough blocks.
+	actual := DarkGravityWave(newTestBlockInfos(10, 60, bits), 24, 60, powLimit)
+	if actual.Cmp(powLimit) != 0 {
+		test.Errorf("consensus.TestDarkGravityWave, not enough blocks:\nactual:\n%x\nexpected:\n%x", actual, powLimit)
+	}
+
+	// Blocks are found on time, difficulty does not change.
+	// The timespan between the first and the last of 24 blocks is 23 intervals.
+	actual = DarkGravityWave(newTestBlockInfos(24, 24, bits), 24, 23, powLimit)
+	if BigToCompact(actual) != bits {
+		test.Errorf("consensus.TestDarkGravityWave, on time:\nactual:\n%x\nexpected:\n%x", BigToCompact(actual), bits)
+	}
+
+	// Blocks are found too fast, target must decrease.
+	actual = DarkGravityWave(newTestBlockInfos(24, 30, bits), 24, 60, powLimit)
+	if actual.Cmp(target) >= 0 {
+		test.Errorf("consensus.TestDarkGravityWave, fast blocks: target %x is not less than %x", actual, target)
+	}
+
+	// Adjustment is limited by factor 3.
+	actual = DarkGravityWave(newTestBlockInfos(24, 1, bits), 24, 60, powLimit)
+	expected := new(big.Int).Div(target, big.NewInt(3))
+	if actual.Cmp(expected) != 0 {
+		test.Errorf("consensus.TestDarkGravityWave, limit:\nactual:\n%x\nexpected:\n%x", actual, expected)
+	}
+
+	// Target never exceeds the limit.
+	actual = DarkGravityWave(newTestBlockInfos(24, 600, 0x1f00ffff), 24, 60, powLimit)
+	if actual.Cmp(powLimit) != 0 {
+		test.Errorf("consensus.TestDarkGravityWave, pow limit:\nactual:\n%x\nexpected:\n%x", actual, powLimit)
+	}
+}
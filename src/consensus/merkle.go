@@ -15,7 +15,16 @@
 
 package consensus
 
-import "crypto/sha256"
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
+)
+
+var ErrMerkleIndexOutOfRange = errors.New("merkle leaf index is out of range")
 
 type MerkleNode struct {
 	Left  *MerkleNode
@@ -23,38 +32,191 @@ type MerkleNode struct {
 	Data  []byte
 }
 
-func ComputeMerkleRoot(data [][]byte) []byte {
-	var nodes []MerkleNode
-	if len(data)%2 != 0 {
-		data = append(data, data[len(data)-1])
+// MerkleTree holds all levels of a merkle tree from hashes of leaves up to
+// the root. A level with an odd number of nodes pairs its last node with
+// itself. The root is always at least one level above the leaves, so a tree
+// of a single leaf hashes it with itself.
+type MerkleTree struct {
+	Levels [][][]byte
+
+	// Mutated is set if two distinct sibling nodes are equal. Such a tree has
+	// the same root as a tree without trailing duplicates (CVE-2012-2459),
+	// e.g. transactions [a, b, c] and [a, b, c, c].
+	Mutated bool
+}
+
+// NewMerkleTree builds a merkle tree of given data.
+func NewMerkleTree(data [][]byte) *MerkleTree {
+	tree := &MerkleTree{}
+	if len(data) == 0 {
+		return tree
 	}
+	var level [][]byte
 	for _, datum := range data {
-		node := newMerkleNode(nil, nil, datum)
-		nodes = append(nodes, *node)
-	}
-	for i := 0; i < len(data)/2; i++ {
-		var newLevel []MerkleNode
-		for j := 0; j < len(nodes); j += 2 {
-			node := newMerkleNode(&nodes[j], &nodes[j+1], nil)
-			newLevel = append(newLevel, *node)
+		level = append(level, hashMerkleLeaf(datum))
+	}
+	tree.Levels = append(tree.Levels, level)
+	for {
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+				if bytes.Equal(level[i], right) {
+					tree.Mutated = true
+				}
+			}
+			next = append(next, hashMerkleNodes(level[i], right))
+		}
+		tree.Levels = append(tree.Levels, next)
+		level = next
+		if len(level) == 1 {
+			break
+		}
+	}
+	return tree
+}
+
+// Root returns the root of the tree, nil if the tree is empty.
+func (t *MerkleTree) Root() []byte {
+	if len(t.Levels) == 0 {
+		return nil
+	}
+	return t.Levels[len(t.Levels)-1][0]
+}
+
+// Proof returns the partial merkle branch which proves inclusion of the leaf
+// with given index.
+func (t *MerkleTree) Proof(index int) (MerkleProof, error) {
+	if len(t.Levels) == 0 || index < 0 || index >= len(t.Levels[0]) {
+		return MerkleProof{}, ErrMerkleIndexOutOfRange
+	}
+	proof := MerkleProof{Index: index, NumLeaves: len(t.Levels[0])}
+	for _, level := range t.Levels[:len(t.Levels)-1] {
+
+		// A node paired with itself needs no sibling in the proof.
+		if sibling := index ^ 1; sibling < len(level) {
+			proof.Hashes = append(proof.Hashes, level[sibling])
 		}
-		nodes = newLevel
+		index /= 2
 	}
-	return nodes[0].Data
+	return proof, nil
+}
+
+// MerkleProof is a compact partial merkle branch: hashes of siblings on the
+// path from a leaf to the root. Siblings of nodes paired with themselves
+// are omitted, since the verifier knows them from the index and the number
+// of leaves.
+type MerkleProof struct {
+	Index     int
+	NumLeaves int
+	Hashes    [][]byte
+}
+
+// Verify checks if the proof connects given leaf data to the root. Proofs
+// of mutated trees, where a node equals its distinct sibling, are rejected.
+func (p MerkleProof) Verify(data, root []byte) bool {
+	if p.Index < 0 || p.Index >= p.NumLeaves {
+		return false
+	}
+	hash := hashMerkleLeaf(data)
+	hashes := p.Hashes
+	index, width := p.Index, p.NumLeaves
+	for {
+		switch {
+		case index%2 == 0 && index+1 == width:
+			hash = hashMerkleNodes(hash, hash)
+		case len(hashes) == 0 || bytes.Equal(hashes[0], hash):
+			return false
+		case index%2 == 0:
+			hash = hashMerkleNodes(hash, hashes[0])
+			hashes = hashes[1:]
+		default:
+			hash = hashMerkleNodes(hashes[0], hash)
+			hashes = hashes[1:]
+		}
+		index /= 2
+		width = (width + 1) / 2
+		if width == 1 {
+			break
+		}
+	}
+	return len(hashes) == 0 && bytes.Equal(hash, root)
+}
+
+// Encode writes the proof: the index and the number of leaves followed by
+// sibling hashes prefixed with their count.
+func (p MerkleProof) Encode(w io.Writer) error {
+	err := wire.WriteVarInt(w, uint64(p.Index))
+	if err != nil {
+		return err
+	}
+	err = wire.WriteVarInt(w, uint64(p.NumLeaves))
+	if err != nil {
+		return err
+	}
+	err = wire.WriteVarInt(w, uint64(len(p.Hashes)))
+	if err != nil {
+		return err
+	}
+	for _, hash := range p.Hashes {
+		err = wire.WriteVarBytes(w, hash)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode reads a proof written by Encode.
+func (p *MerkleProof) Decode(r io.Reader) error {
+	var err error
+	p.Index, err = wire.ReadCount(r)
+	if err != nil {
+		return err
+	}
+	p.NumLeaves, err = wire.ReadCount(r)
+	if err != nil {
+		return err
+	}
+	count, err := wire.ReadCount(r)
+	if err != nil {
+		return err
+	}
+	p.Hashes = nil
+	for i := 0; i < count; i++ {
+		hash, err := wire.ReadVarBytes(r)
+		if err != nil {
+			return err
+		}
+		p.Hashes = append(p.Hashes, hash)
+	}
+	return nil
+}
+
+// ComputeMerkleRoot returns the root of the merkle tree of given data.
+func ComputeMerkleRoot(data [][]byte) []byte {
+	return NewMerkleTree(data).Root()
+}
+
+func hashMerkleLeaf(data []byte) []byte {
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+func hashMerkleNodes(left, right []byte) []byte {
+	hash := sha256.Sum256(append(append([]byte{}, left...), right...))
+	return hash[:]
 }
 
 func newMerkleNode(left, right *MerkleNode, data []byte) *MerkleNode {
 	mNode := MerkleNode{}
 	if left == nil && right == nil {
-		hash := sha256.Sum256(data)
-		mNode.Data = hash[:]
+		mNode.Data = hashMerkleLeaf(data)
 	} else {
-		prevHashes := append(left.Data, right.Data...)
-		hash := sha256.Sum256(prevHashes)
-		mNode.Data = hash[:]
+		mNode.Data = hashMerkleNodes(left.Data, right.Data)
 	}
 	mNode.Left = left
 	mNode.Right = right
 	return &mNode
 }
-
@@ -16,8 +16,10 @@
 package consensus
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -78,3 +80,84 @@ func TestNewMerkleTree(test *testing.T) {
 		test.Error("Merkle tree root hash is incorrect")
 	}
 }
+
+func newTestLeaves(count int) [][]byte {
+	var data [][]byte
+	for i := 0; i < count; i++ {
+		data = append(data, []byte(fmt.Sprintf("node%d", i+1)))
+	}
+	return data
+}
+
+func TestMerkleTree_Proof(test *testing.T) {
+	for count := 1; count <= 17; count++ {
+		data := newTestLeaves(count)
+		tree := NewMerkleTree(data)
+		if tree.Mutated {
+			test.Errorf("consensus.TestMerkleTree_Proof, %d leaves: tree is mutated", count)
+		}
+		root := tree.Root()
+		for i := range data {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				test.Fatalf("consensus.TestMerkleTree_Proof, %d leaves, index %d: %v", count, i, err)
+			}
+			if !proof.Verify(data[i], root) {
+				test.Errorf("consensus.TestMerkleTree_Proof, %d leaves, index %d: valid proof is rejected", count, i)
+			}
+			other := data[(i+1)%count]
+			if count > 1 && proof.Verify(other, root) {
+				test.Errorf("consensus.TestMerkleTree_Proof, %d leaves, index %d: proof of another leaf is accepted", count, i)
+			}
+			if len(proof.Hashes) > 0 {
+				forged := proof
+				forged.Hashes = append([][]byte{}, proof.Hashes[1:]...)
+				if forged.Verify(data[i], root) {
+					test.Errorf("consensus.TestMerkleTree_Proof, %d leaves, index %d: truncated proof is accepted", count, i)
+				}
+			}
+
+			var buff bytes.Buffer
+			err = proof.Encode(&buff)
+			if err != nil {
+				test.Fatal(err)
+			}
+			var decoded MerkleProof
+			err = decoded.Decode(&buff)
+			if err != nil || !reflect.DeepEqual(decoded, proof) {
+				test.Errorf("consensus.TestMerkleTree_Proof, %d leaves, index %d:\nactual:\n%v, %v\nexpected:\n%v", count, i, decoded, err, proof)
+			}
+		}
+		if _, err := tree.Proof(count); err != ErrMerkleIndexOutOfRange {
+			test.Errorf("consensus.TestMerkleTree_Proof, %d leaves:\nactual:\n%v\nexpected:\n%v", count, err, ErrMerkleIndexOutOfRange)
+		}
+	}
+}
+
+func TestMerkleTree_Mutated(test *testing.T) {
+	data := newTestLeaves(3)
+	tree := NewMerkleTree(data)
+	mutated := NewMerkleTree(append(data, data[2]))
+	if !bytes.Equal(tree.Root(), mutated.Root()) {
+		test.Fatal("consensus.TestMerkleTree_Mutated: trees have different roots")
+	}
+	if tree.Mutated || !mutated.Mutated {
+		test.Errorf("consensus.TestMerkleTree_Mutated:\nactual:\n%t, %t\nexpected:\nfalse, true", tree.Mutated, mutated.Mutated)
+	}
+
+	// The duplicated leaf can not be proven as a separate one.
+	proof, err := mutated.Proof(3)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if proof.Verify(data[2], mutated.Root()) {
+		test.Errorf("consensus.TestMerkleTree_Mutated: proof of the duplicated leaf is accepted")
+	}
+
+	// Duplicates of larger subtrees are detected at upper levels.
+	data = newTestLeaves(6)
+	mutated = NewMerkleTree(append(data, data[4:]...))
+	if !bytes.Equal(NewMerkleTree(data).Root(), mutated.Root()) || !mutated.Mutated {
+		test.Errorf("consensus.TestMerkleTree_Mutated: subtree duplication is not detected")
+	}
+}
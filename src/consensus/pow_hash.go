@@ -0,0 +1,54 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package consensus
+
+import (
+	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/sha3/groestl512"
+	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/x11"
+)
+
+// PowHasher computes hashes of serialized block headers, which are
+// compared with the target. Implementations must be safe for concurrent use.
+type PowHasher interface {
+	Hash(data []byte) []byte
+}
+
+// X11Hasher hashes headers with the chain of eleven X11 functions.
+type X11Hasher struct{}
+
+func (X11Hasher) Hash(data []byte) []byte {
+	hash := x11.Sum256(data)
+	return hash[:]
+}
+
+// GroestlHasher hashes headers the way Groestlcoin does.
+type GroestlHasher struct{}
+
+func (GroestlHasher) Hash(data []byte) []byte {
+	return GroestlHash(data)
+}
+
+// GroestlHash computes Groestl-512 of Groestl-512 of the data and returns
+// the first 32 bytes.
+func GroestlHash(data []byte) []byte {
+	var first, second [64]byte
+	digest := groestl512.New()
+	digest.Write(data)
+	digest.Close(first[:], 0, 0)
+	digest.Write(first[:])
+	digest.Close(second[:], 0, 0)
+	return second[:32]
+}
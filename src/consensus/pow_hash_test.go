@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package consensus
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// The genesis block header of Groestlcoin, its hash is displayed in
+// reversed byte order as 00000ac5927c594d49cc0bdb81759d0da8297eb614683d3acb62f0703b639023.
+const groestlcoinGenesisHeader = "70000000000000000000000000000000000000000000000000000000000000000000000" +
+	"0bb2866aaca46c4428ad08b57bc9d1493abaf64724b6c3052a7c8f958df68e93ced3d2b53ffff0f1e835b0300"
+
+var PowHasher_Data = []struct {
+	name     string
+	hasher   PowHasher
+	data     string
+	expected string
+}{
+	{
+		name:     "x11",
+		hasher:   X11Hasher{},
+		data:     hex.EncodeToString([]byte("The great experiment continues")),
+		expected: "e05103283876cfa7254683f678f0b1a4c3621ffdd51b78bad2fa134b4875c936",
+	},
+	{
+		name:     "groestl",
+		hasher:   GroestlHasher{},
+		data:     groestlcoinGenesisHeader,
+		expected: "2390633b70f062cb3a3d6814b67e29a80d9d7581db0bcc494d597c92c50a0000",
+	},
+}
+
+func TestPowHasher_Hash(test *testing.T) {
+	for _, data := range PowHasher_Data {
+		input, err := hex.DecodeString(data.data)
+		if err != nil {
+			test.Fatal(err)
+		}
+		expected, _ := hex.DecodeString(data.expected)
+		actual := data.hasher.Hash(input)
+		if !bytes.Equal(actual, expected) {
+			test.Errorf("consensus.TestPowHasher_Hash, %s:\nactual:\n%x\nexpected:\n%x", data.name, actual, expected)
+		}
+	}
+}
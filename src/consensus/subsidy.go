@@ -0,0 +1,76 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package consensus
+
+// SubsidyFunc returns the amount of new coins which a block at given height
+// is allowed to create.
+type SubsidyFunc func(height int) int64
+
+// HalvingSubsidy returns a schedule which starts with the initial subsidy
+// and halves it every interval blocks, so the total supply is capped at
+// about 2 * initial * interval.
+func HalvingSubsidy(initial int64, interval int) SubsidyFunc {
+	return func(height int) int64 {
+		return initial >> uint(height/interval)
+	}
+}
+
+// GroestlcoinSubsidy returns the decaying schedule of Groestlcoin with
+// amounts expressed in given coin unit. The subsidy decreases several times
+// per era and never drops below the tail emission of 5 coins.
+func GroestlcoinSubsidy(coin int64) SubsidyFunc {
+	minimum := 5 * coin
+	return func(height int) int64 {
+		switch {
+		case height == 0:
+			return 1 * coin
+		case height == 1:
+
+			// Premine.
+			return 240640 * coin
+		case height < 120000:
+
+			// 6% less every week (10080 blocks).
+			subsidy := 512 * coin
+			for i := 0; i < height/10080; i++ {
+				subsidy = subsidy * 47 / 50
+			}
+			if subsidy < minimum {
+				return minimum
+			}
+			return subsidy
+		case height < 150000:
+
+			// 10% less every day (1440 blocks).
+			subsidy := 250 * coin
+			for i := 0; i < (height-120000)/1440; i++ {
+				subsidy = subsidy * 45 / 50
+			}
+			return subsidy
+		default:
+
+			// 1% less every week until the tail emission is reached.
+			subsidy := 25 * coin
+			for i := 0; i < (height-150000)/10080 && subsidy >= minimum; i++ {
+				subsidy = subsidy * 99 / 100
+			}
+			if subsidy < minimum {
+				return minimum
+			}
+			return subsidy
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package consensus
+
+import "testing"
+
+const testCoin = 100000000
+
+var Subsidy_Data = []struct {
+	name     string
+	subsidy  SubsidyFunc
+	height   int
+	expected int64
+}{
+	{"halving, genesis", HalvingSubsidy(50*testCoin, 210000), 0, 50 * testCoin},
+	{"halving, last block of era", HalvingSubsidy(50*testCoin, 210000), 209999, 50 * testCoin},
+	{"halving, first halving", HalvingSubsidy(50*testCoin, 210000), 210000, 25 * testCoin},
+	{"halving, third halving", HalvingSubsidy(50*testCoin, 210000), 630000, 625000000},
+	{"halving, exhausted", HalvingSubsidy(50*testCoin, 210000), 64 * 210000, 0},
+	{"halving, short interval", HalvingSubsidy(50*testCoin, 150), 300, 1250000000},
+	{"groestlcoin, genesis", GroestlcoinSubsidy(testCoin), 0, 1 * testCoin},
+	{"groestlcoin, premine", GroestlcoinSubsidy(testCoin), 1, 240640 * testCoin},
+	{"groestlcoin, launch", GroestlcoinSubsidy(testCoin), 2, 512 * testCoin},
+	{"groestlcoin, first week", GroestlcoinSubsidy(testCoin), 10080, 48128000000},
+	{"groestlcoin, daily decay", GroestlcoinSubsidy(testCoin), 120000, 250 * testCoin},
+	{"groestlcoin, second day", GroestlcoinSubsidy(testCoin), 121440, 225 * testCoin},
+	{"groestlcoin, weekly decay", GroestlcoinSubsidy(testCoin), 150000, 25 * testCoin},
+	{"groestlcoin, second week", GroestlcoinSubsidy(testCoin), 160080, 2475000000},
+	{"groestlcoin, tail emission", GroestlcoinSubsidy(testCoin), 10000000, 5 * testCoin},
+}
+
+func TestSubsidy(test *testing.T) {
+	for _, data := range Subsidy_Data {
+		actual := data.subsidy(data.height)
+		if actual != data.expected {
+			test.Errorf("consensus.TestSubsidy, %s:\nactual:\n%d\nexpected:\n%d", data.name, actual, data.expected)
+		}
+	}
+}
+
+func TestHalvingSubsidy_Supply(test *testing.T) {
+	const interval = 210000
+	subsidy := HalvingSubsidy(50*testCoin, interval)
+	total := int64(0)
+	for height := 0; subsidy(height) > 0; height += interval {
+		total += subsidy(height) * interval
+	}
+	if max := int64(21000000 * testCoin); total > max {
+		test.Errorf("consensus.TestHalvingSubsidy_Supply:\nactual:\n%d\nexpected less than:\n%d", total, max)
+	}
+}
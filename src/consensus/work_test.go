@@ -0,0 +1,48 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package consensus
+
+import (
+	"math/big"
+	"testing"
+)
+
+var CalcWork_Data = []struct {
+	target   *big.Int
+	expected *big.Int
+}{
+	{
+		target:   new(big.Int).Lsh(big.NewInt(1), 240),
+		expected: big.NewInt(65535),
+	},
+	{
+		target:   new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1)),
+		expected: big.NewInt(2),
+	},
+	{
+		target:   big.NewInt(0),
+		expected: big.NewInt(0),
+	},
+}
+
+func TestCalcWork(test *testing.T) {
+	for i, data := range CalcWork_Data {
+		actual := CalcWork(data.target)
+		if actual.Cmp(data.expected) != 0 {
+			test.Errorf("consensus.TestCalcWork[%d]:\nactual:\n%s\nexpected:\n%s", i, actual, data.expected)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"log"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+var ErrAddrIndexDisabled = errors.New("address index is disabled")
+
+// AddressTx is a main chain transaction which funds or spends an address.
+type AddressTx struct {
+	TxHash   []byte
+	Height   int
+	Position int
+}
+
+// addressHash returns the public key hash or the script hash locking given
+// output, nil if the output is not locked to an address.
+func addressHash(scriptPubKey []byte) []byte {
+	if hash := script.ExtractPubKeyHash(scriptPubKey); hash != nil {
+		return hash
+	}
+	return script.ExtractScriptHash(scriptPubKey)
+}
+
+// addrIndexKey builds a key of the address index. Keys of an address start
+// with its hash and are ordered by height and position within the block.
+func addrIndexKey(hash []byte, height, pos int) []byte {
+	key := append(append([]byte{}, hash...), heightKey(height)...)
+	posKey := make([]byte, 4)
+	binary.BigEndian.PutUint32(posKey, uint32(pos))
+	return append(key, posKey...)
+}
+
+// addrIndexEntries returns keys of the address index pointing to block's
+// transactions along with hashes of the transactions. Outputs spent by the
+// block must be given in the order they were spent.
+func addrIndexEntries(block types.Block, spent []SpentOutput) ([][]byte, [][]byte) {
+	var keys, values [][]byte
+	add := func(scriptPubKey []byte, pos int, txHash []byte) {
+		if hash := addressHash(scriptPubKey); hash != nil {
+			keys = append(keys, addrIndexKey(hash, block.Height, pos))
+			values = append(values, txHash)
+		}
+	}
+	spentIdx := 0
+	for pos, tx := range block.Transactions {
+		if tx.IsCoinBase() == false {
+			for range tx.VIn {
+				if spentIdx < len(spent) {
+					add(spent[spentIdx].Output.ScriptPubKey, pos, tx.Hash)
+				}
+				spentIdx++
+			}
+		}
+		for _, out := range tx.VOut {
+			add(out.ScriptPubKey, pos, tx.Hash)
+		}
+	}
+	return keys, values
+}
+
+// indexAddresses adds block's transactions to the address index.
+func indexAddresses(b *db_pkg.Bucket, block types.Block, spent []SpentOutput) error {
+	keys, values := addrIndexEntries(block, spent)
+	for i, key := range keys {
+		err := b.Put(key, values[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unindexAddresses removes block's transactions from the address index.
+func unindexAddresses(b *db_pkg.Bucket, block types.Block, spent []SpentOutput) error {
+	keys, _ := addrIndexEntries(block, spent)
+	for _, key := range keys {
+		err := b.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasAddrIndex checks if the address index is maintained in the database.
+func (bc *BlockChain) HasAddrIndex() bool {
+	found := false
+	err := bc.db.View(func(tx *db_pkg.Tx) error {
+		found = tx.Bucket(utils.ADDR_INDEX_BUCKET) != nil
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+	return found
+}
+
+// ReindexAddresses builds the address index from scratch. Outputs spent by
+// main chain blocks are taken from their undo records.
+func (bc *BlockChain) ReindexAddresses() {
+	bc.rebuildIndex(utils.ADDR_INDEX_BUCKET, func(dbTx *db_pkg.Tx, b *db_pkg.Bucket, block types.Block) error {
+		undoBucket := dbTx.Bucket(vars.UNDO_BUCKET)
+		if undoBucket == nil {
+			return ErrMissingUndoData
+		}
+		undoBytes := undoBucket.Get(block.Hash)
+		if undoBytes == nil {
+			return ErrMissingUndoData
+		}
+		return indexAddresses(b, block, DeserializeBlockUndo(undoBytes).SpentOutputs)
+	})
+}
+
+// DropAddrIndex removes the address index from the database.
+func (bc *BlockChain) DropAddrIndex() {
+	bc.dropIndex(utils.ADDR_INDEX_BUCKET)
+}
+
+// GetAddressHistory returns main chain transactions which fund or spend an
+// address with given public key hash or script hash, ordered from the oldest
+// one. A transaction is listed once even if it touches the address several times.
+func (bc *BlockChain) GetAddressHistory(hash []byte) ([]AddressTx, error) {
+	var history []AddressTx
+	err := bc.db.View(func(tx *db_pkg.Tx) error {
+		b := tx.Bucket(utils.ADDR_INDEX_BUCKET)
+		if b == nil {
+			return ErrAddrIndexDisabled
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(hash); k != nil && bytes.HasPrefix(k, hash); k, v = c.Next() {
+
+			// Skip keys of longer hashes sharing the prefix.
+			if len(k) != len(hash)+8 {
+				continue
+			}
+			history = append(history, AddressTx{
+				TxHash:   append([]byte{}, v...),
+				Height:   int(binary.BigEndian.Uint32(k[len(hash):])),
+				Position: int(binary.BigEndian.Uint32(k[len(hash)+4:])),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+func dumpAddressHistory(test *testing.T, bc BlockChain, hash []byte) string {
+	history, err := bc.GetAddressHistory(hash)
+	if err != nil {
+		test.Fatal(err)
+	}
+	result := ""
+	for _, item := range history {
+		result += fmt.Sprintf("%s@%d:%d ", item.TxHash, item.Height, item.Position)
+	}
+	return result
+}
+
+func TestBlockChain_GetAddressHistory(test *testing.T) {
+	bc, cleanup := newTestBlockChain(test, vars.UTXO_BUCKET, utils.ADDR_INDEX_BUCKET)
+	defer cleanup()
+	utxoSet := UTXOSet{BlockChain: bc}
+
+	alice, bob := bytes.Repeat([]byte{0x01}, 20), bytes.Repeat([]byte{0x02}, 20)
+	payTo := func(hash []byte, value int) tx_io.TXOutput {
+		scriptPubKey, err := script.PayToPubKeyHashScript(hash)
+		if err != nil {
+			test.Fatal(err)
+		}
+		return tx_io.TXOutput{Value: money.Amount(value), ScriptPubKey: scriptPubKey}
+	}
+	coinBase := []tx_io.TXInput{{PreviousTx: []byte{}, VOut: -1}}
+	block1 := types.Block{Hash: []byte("block1"), Height: 0, Transactions: []types.Transaction{
+		{Hash: []byte("cb1"), VIn: coinBase, VOut: []tx_io.TXOutput{payTo(alice, 50)}},
+	}}
+
+	// Alice pays Bob and gets a change, so the transaction funds and
+	// spends her address at the same time.
+	block2 := types.Block{Hash: []byte("block2"), Height: 1, Transactions: []types.Transaction{
+		{Hash: []byte("cb2"), VIn: coinBase, VOut: []tx_io.TXOutput{payTo(bob, 50)}},
+		{Hash: []byte("tx1"), VIn: []tx_io.TXInput{{PreviousTx: []byte("cb1"), VOut: 0}}, VOut: []tx_io.TXOutput{payTo(bob, 20), payTo(alice, 30)}},
+		{Hash: []byte("tx2"), VIn: []tx_io.TXInput{{PreviousTx: []byte("tx1"), VOut: 0}}, VOut: []tx_io.TXOutput{{Value: 20, ScriptPubKey: []byte("nonstandard")}}},
+	}}
+	utxoSet.Update(block1)
+	utxoSet.Update(block2)
+
+	data := []struct {
+		name     string
+		hash     []byte
+		expected string
+	}{
+		{"alice", alice, "cb1@0:0 tx1@1:1 "},
+		{"bob", bob, "cb2@1:0 tx1@1:1 tx2@1:2 "},
+		{"unknown", bytes.Repeat([]byte{0x03}, 20), ""},
+	}
+	for _, item := range data {
+		if actual := dumpAddressHistory(test, bc, item.hash); actual != item.expected {
+			test.Errorf("core.TestBlockChain_GetAddressHistory, %s:\nactual:\n%s\nexpected:\n%s", item.name, actual, item.expected)
+		}
+	}
+
+	// Transactions of a disconnected block are removed from the index.
+	utxoSet.Disconnect(block2)
+	if actual := dumpAddressHistory(test, bc, alice); actual != "cb1@0:0 " {
+		test.Errorf("core.TestBlockChain_GetAddressHistory, disconnected:\nactual:\n%s\nexpected:\n%s", actual, "cb1@0:0 ")
+	}
+	if actual := dumpAddressHistory(test, bc, bob); actual != "" {
+		test.Errorf("core.TestBlockChain_GetAddressHistory, disconnected:\nactual:\n%s\nexpected:\n%s", actual, "")
+	}
+
+	bc.DropAddrIndex()
+	if _, err := bc.GetAddressHistory(alice); err != ErrAddrIndexDisabled {
+		test.Errorf("core.TestBlockChain_GetAddressHistory, dropped:\nactual:\n%v\nexpected:\n%v", err, ErrAddrIndexDisabled)
+	}
+}
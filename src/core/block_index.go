@@ -0,0 +1,184 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+// Block index entry status flags.
+const (
+	BLOCK_FAILED_VALID = uint8(1 << iota)
+	BLOCK_HAVE_DATA
+)
+
+// BlockIndexEntry describes a block known to the node, whether it belongs
+// to the main chain or to a side branch. Entry keeps block's header, so
+// headers can be queried without loading transactions, and can exist
+// before block's transactions are received.
+type BlockIndexEntry struct {
+	Hash      []byte
+	Header    types.BlockHeader
+	Height    int
+	ChainWork *big.Int
+	Status    uint8
+}
+
+func newBlockIndexEntry(header types.BlockHeader, prev *BlockIndexEntry) BlockIndexEntry {
+	worker := NewProofOfWork(header)
+	chainWork := worker.Work()
+	height := 0
+	if prev != nil {
+		chainWork.Add(chainWork, prev.ChainWork)
+		height = prev.Height + 1
+	}
+	return BlockIndexEntry{
+		Hash:      header.Hash(),
+		Header:    header,
+		Height:    height,
+		ChainWork: chainWork,
+	}
+}
+
+// Failed checks if the block or one of its ancestors failed validation.
+func (e BlockIndexEntry) Failed() bool {
+	return e.Status&BLOCK_FAILED_VALID != 0
+}
+
+// HaveData checks if block's transactions are stored in the database.
+func (e BlockIndexEntry) HaveData() bool {
+	return e.Status&BLOCK_HAVE_DATA != 0
+}
+
+func (e BlockIndexEntry) Serialize() []byte {
+	var result bytes.Buffer
+	encoder := gob.NewEncoder(&result)
+	err := encoder.Encode(e)
+	if err != nil {
+		log.Panic(err)
+	}
+	return result.Bytes()
+}
+
+func DeserializeBlockIndexEntry(data []byte) BlockIndexEntry {
+	var entry BlockIndexEntry
+	decoder := gob.NewDecoder(bytes.NewReader(data))
+	err := decoder.Decode(&entry)
+	if err != nil {
+		log.Panic(err)
+	}
+	return entry
+}
+
+// GetBlockIndexEntry retrieves index entry of a block by given hash.
+func (bc *BlockChain) GetBlockIndexEntry(blockHash []byte) (BlockIndexEntry, error) {
+	data, err := bc.db.Get(blockHash, utils.BLOCK_INDEX_BUCKET)
+	if err != nil {
+		return BlockIndexEntry{}, err
+	}
+	return DeserializeBlockIndexEntry(data), nil
+}
+
+func getBlockIndexEntry(b *db_pkg.Bucket, blockHash []byte) (BlockIndexEntry, error) {
+	data := b.Get(blockHash)
+	if data == nil {
+		return BlockIndexEntry{}, errors.New(fmt.Sprintf("block %x is not indexed", blockHash))
+	}
+	return DeserializeBlockIndexEntry(data), nil
+}
+
+// initBlockIndex builds the block index for a database which was created
+// without it. Only blocks of the main chain are indexed.
+func (bc *BlockChain) initBlockIndex() {
+	var blocks []types.Block
+	bci := bc.Iterator()
+	for !bci.End() {
+		blocks = append([]types.Block{bci.Next()}, blocks...)
+	}
+	err := bc.db.Update(func(tx *db_pkg.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(utils.BLOCK_INDEX_BUCKET)
+		if err != nil {
+			return err
+		}
+		var prev *BlockIndexEntry
+		for _, block := range blocks {
+			entry := newBlockIndexEntry(block.BlockHeader, prev)
+			entry.Status |= BLOCK_HAVE_DATA
+			err = b.Put(entry.Hash, entry.Serialize())
+			if err != nil {
+				return err
+			}
+			prev = &entry
+		}
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// markBlockFailed marks given block as invalid, so its descendants are rejected.
+func (bc *BlockChain) markBlockFailed(blockHash []byte) {
+	err := bc.db.Update(func(tx *db_pkg.Tx) error {
+		b := tx.Bucket(utils.BLOCK_INDEX_BUCKET)
+		entry, err := getBlockIndexEntry(b, blockHash)
+		if err != nil {
+			return err
+		}
+		entry.Status |= BLOCK_FAILED_VALID
+		return b.Put(entry.Hash, entry.Serialize())
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// findFork walks back from both tips to their common ancestor. It returns hashes
+// of the blocks to disconnect, starting from the old tip, and hashes of the blocks
+// to connect, starting from the one next to the fork point.
+func findFork(b *db_pkg.Bucket, oldTip, newTip []byte) ([][]byte, [][]byte, error) {
+	var detach, attach [][]byte
+	oldEntry, err := getBlockIndexEntry(b, oldTip)
+	if err != nil {
+		return nil, nil, err
+	}
+	newEntry, err := getBlockIndexEntry(b, newTip)
+	if err != nil {
+		return nil, nil, err
+	}
+	for bytes.Compare(oldEntry.Hash, newEntry.Hash) != 0 {
+		if oldEntry.Height >= newEntry.Height {
+			detach = append(detach, oldEntry.Hash)
+			oldEntry, err = getBlockIndexEntry(b, oldEntry.Header.PrevBlockHash)
+		} else {
+			attach = append([][]byte{newEntry.Hash}, attach...)
+			newEntry, err = getBlockIndexEntry(b, newEntry.Header.PrevBlockHash)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return detach, attach, nil
+}
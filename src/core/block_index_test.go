@@ -0,0 +1,84 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+// Index of the test tree:
+//
+//   a - b - c - d
+//        \
+//         e - f
+var testBlockIndex = []BlockIndexEntry{
+	{Hash: []byte("a"), Header: types.BlockHeader{PrevBlockHash: []byte{}}, Height: 0},
+	{Hash: []byte("b"), Header: types.BlockHeader{PrevBlockHash: []byte("a")}, Height: 1},
+	{Hash: []byte("c"), Header: types.BlockHeader{PrevBlockHash: []byte("b")}, Height: 2},
+	{Hash: []byte("d"), Header: types.BlockHeader{PrevBlockHash: []byte("c")}, Height: 3},
+	{Hash: []byte("e"), Header: types.BlockHeader{PrevBlockHash: []byte("b")}, Height: 2},
+	{Hash: []byte("f"), Header: types.BlockHeader{PrevBlockHash: []byte("e")}, Height: 3},
+}
+
+var FindFork_Data = []struct {
+	oldTip string
+	newTip string
+	detach string
+	attach string
+}{
+	{oldTip: "d", newTip: "f", detach: "dc", attach: "ef"},
+	{oldTip: "f", newTip: "d", detach: "fe", attach: "cd"},
+	{oldTip: "c", newTip: "d", detach: "", attach: "d"},
+	{oldTip: "d", newTip: "e", detach: "dc", attach: "e"},
+	{oldTip: "d", newTip: "d", detach: "", attach: ""},
+}
+
+func TestFindFork(test *testing.T) {
+	bc, cleanup := newTestBlockChain(test, utils.BLOCK_INDEX_BUCKET)
+	defer cleanup()
+	db := bc.db
+	for _, entry := range testBlockIndex {
+		entry.ChainWork = big.NewInt(int64(entry.Height))
+		err := db.Put(entry.Hash, entry.Serialize(), utils.BLOCK_INDEX_BUCKET, false)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+	for i, data := range FindFork_Data {
+		err := db.View(func(tx *db_pkg.Tx) error {
+			detach, attach, err := findFork(tx.Bucket(utils.BLOCK_INDEX_BUCKET), []byte(data.oldTip), []byte(data.newTip))
+			if err != nil {
+				return err
+			}
+			if actual := bytes.Join(detach, nil); string(actual) != data.detach {
+				test.Errorf("core.TestFindFork[%d], detach:\nactual:\n%s\nexpected:\n%s", i, actual, data.detach)
+			}
+			if actual := bytes.Join(attach, nil); string(actual) != data.attach {
+				test.Errorf("core.TestFindFork[%d], attach:\nactual:\n%s\nexpected:\n%s", i, actual, data.attach)
+			}
+			return nil
+		})
+		if err != nil {
+			test.Error(err)
+		}
+	}
+}
@@ -17,19 +17,24 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"time"
 
 	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
 	"github.com/YuriyLisovskiy/blockchain-go/src/config"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
 	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
 	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
 )
 
@@ -38,17 +43,15 @@ type BlockChain struct {
 	db  *db_pkg.DB
 }
 
-func CreateBlockChain(address string, cfg config.Config) BlockChain {
+// CreateBlockChain creates a database holding the genesis block
+// of the active network.
+func CreateBlockChain(cfg config.Config) BlockChain {
 	utils.DBFile = cfg.ChainPath
 	if utils.DBExists(utils.DBFile) {
 		fmt.Printf("%s already exists.\n", utils.DBFile)
 		os.Exit(1)
 	}
-	cbTx := NewCoinBaseTX(address, 0)
-	genesis, err := NewGenesisBlock(cbTx)
-	if err != nil {
-		log.Panic(err)
-	}
+	genesis := NewGenesisBlock(params.Active())
 	db, err := db_pkg.Open(utils.DBFile, 0600, nil)
 	if err != nil {
 		log.Panic(err)
@@ -56,12 +59,39 @@ func CreateBlockChain(address string, cfg config.Config) BlockChain {
 	keys := [][]byte{
 		genesis.Hash,
 		utils.LAST_BLOCK_HASH,
+		utils.DB_VERSION,
 	}
 	values := [][]byte{
-		genesis.Serialize(), // genesis.Hash
-		genesis.Hash,        // utils.LAST_BLOCK_HASH
+		genesis.Serialize(),              // genesis.Hash
+		genesis.Hash,                     // utils.LAST_BLOCK_HASH
+		encodeDBVersion(vars.DB_VERSION), // utils.DB_VERSION
 	}
 	err = db.PutArray(keys, values, utils.BLOCKS_BUCKET, false)
+	if err != nil {
+		log.Panic(err)
+	}
+	genesisEntry := newBlockIndexEntry(genesis.BlockHeader, nil)
+	genesisEntry.Status |= BLOCK_HAVE_DATA
+	err = db.Put(genesis.Hash, genesisEntry.Serialize(), utils.BLOCK_INDEX_BUCKET, false)
+	if err == nil {
+
+		// Optional indexes are filled when the genesis block is connected.
+		err = db.Update(func(tx *db_pkg.Tx) error {
+			if cfg.TxIndex {
+				_, err := tx.CreateBucketIfNotExists(utils.TX_INDEX_BUCKET)
+				if err != nil {
+					return err
+				}
+			}
+			if cfg.AddrIndex {
+				_, err := tx.CreateBucketIfNotExists(utils.ADDR_INDEX_BUCKET)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
 
 	/*
 		err = db.Update(func(tx *db_pkg.Tx) error {
@@ -108,56 +138,137 @@ func NewBlockChain(cfg config.Config) BlockChain {
 	if err != nil {
 		log.Panic(err)
 	}
-	return BlockChain{tip, db}
+	bc := BlockChain{tip, db}
+	err = bc.migrateDB()
+	if err == ErrResyncRequired {
+		fmt.Printf("%s: %s. Remove it and create the block chain again.\n", utils.DBFile, err)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// Databases created by older versions lack the block index, undo records
+	// and the height index.
+	var hasIndex, hasUndo, hasHeights bool
+	err = db.View(func(tx *db_pkg.Tx) error {
+		hasIndex = tx.Bucket(utils.BLOCK_INDEX_BUCKET) != nil
+		hasUndo = tx.Bucket(vars.UNDO_BUCKET) != nil
+		hasHeights = tx.Bucket(utils.HEIGHT_INDEX_BUCKET) != nil
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+	if !hasIndex {
+		bc.initBlockIndex()
+	}
+	if !hasUndo || !hasHeights {
+		UTXOSet{BlockChain: bc}.Reindex()
+	}
+
+	// A database of another network can not be used.
+	genesisHash, err := bc.GetBlockHashByHeight(0)
+	if err != nil {
+		log.Panic(err)
+	}
+	if !bytes.Equal(genesisHash, params.Active().GenesisHash) {
+		fmt.Printf("%s does not belong to %s network.\n", utils.DBFile, params.Active().Name)
+		os.Exit(1)
+	}
+	if cfg.MaxTimeDrift > 0 {
+		SetMaxFutureBlockTime(cfg.MaxTimeDrift)
+	}
+	if cfg.MinerThreads > 0 {
+		SetMiningThreads(cfg.MinerThreads)
+	}
+
+	// Transaction and address indexes are optional, they are built
+	// or removed when the configuration changes.
+	if cfg.TxIndex && !bc.HasTxIndex() {
+		bc.ReindexTransactions()
+	} else if !cfg.TxIndex && bc.HasTxIndex() {
+		bc.DropTxIndex()
+	}
+	if cfg.AddrIndex && !bc.HasAddrIndex() {
+		bc.ReindexAddresses()
+	} else if !cfg.AddrIndex && bc.HasAddrIndex() {
+		bc.DropAddrIndex()
+	}
+	return bc
 }
 
-// AddBlock writes given block to the database if it does not exist.
-func (bc *BlockChain) AddBlock(block types.Block) {
+// AddBlock validates given block and writes it to the database if it does not exist.
+// The block becomes the new tip if its branch has the most cumulative work;
+// the UTXO set is updated accordingly.
+func (bc *BlockChain) AddBlock(block types.Block) error {
+
+	// Lock thread while checking and changing database content.
+	vars.DBMutex.Lock()
+	defer vars.DBMutex.Unlock()
 
 	// Check if given block already exists in the database.
-	// If exists then returns from function, else performs adding new block logic.
 	blockInDb, err := bc.db.Get(block.Hash, utils.BLOCKS_BUCKET)
 	if blockInDb != nil {
-		return
+		return ErrBlockExists
 	}
 	if err != nil && err != db_pkg.ErrKeyNotFound {
-		log.Panic(err)
+		return err
 	}
-
-	// Lock thread while changing database content.
-	vars.DBMutex.Lock()
+	err = bc.ValidateBlock(block)
+	if err != nil {
+		return err
+	}
+	prevEntry, err := bc.GetBlockIndexEntry(block.PrevBlockHash)
+	if err != nil {
+		return err
+	}
+	entry := newBlockIndexEntry(block.BlockHeader, &prevEntry)
+	entry.Status |= BLOCK_HAVE_DATA
+	var tipEntry BlockIndexEntry
 	err = bc.db.Batch(func(tx *db_pkg.Tx) error {
 		b := tx.Bucket(utils.BLOCKS_BUCKET)
 
 		// Write new block to the database
-		blockData := block.Serialize()
-		err := b.Put(block.Hash, blockData)
+		err := b.Put(block.Hash, block.Serialize())
 		if err != nil {
-			log.Panic(err)
+			return err
+		}
+		index := tx.Bucket(utils.BLOCK_INDEX_BUCKET)
+		err = index.Put(entry.Hash, entry.Serialize())
+		if err != nil {
+			return err
+		}
+		tipEntry, err = getBlockIndexEntry(index, b.Get(utils.LAST_BLOCK_HASH))
+		if err != nil {
+			return err
 		}
 
-		// Check if given block is the newest one.
-		lastHash := b.Get(utils.LAST_BLOCK_HASH)
-		lastBlockData := b.Get(lastHash)
-		lastBlock := DeserializeBlock(lastBlockData)
-		if block.Height > lastBlock.Height {
-			err = b.Put(utils.LAST_BLOCK_HASH, block.Hash)
-			if err != nil {
-				log.Panic(err)
-			}
-			//	bc.tip = block.Hash
+		// A block which extends the tip is already fully validated,
+		// so it can be connected right away.
+		if bytes.Compare(block.PrevBlockHash, tipEntry.Hash) != 0 {
+			return nil
 		}
-		return nil
+		err = UTXOSet{BlockChain: *bc}.connect(tx, block)
+		if err != nil {
+			return err
+		}
+		return b.Put(utils.LAST_BLOCK_HASH, block.Hash)
 	})
 	if err != nil {
-		log.Panic(err)
+		return err
 	}
-	vars.DBMutex.Unlock()
+	if bytes.Compare(block.PrevBlockHash, tipEntry.Hash) == 0 || entry.ChainWork.Cmp(tipEntry.ChainWork) <= 0 {
+		return nil
+	}
+
+	// The block's branch has more cumulative work than the main chain.
+	return bc.reorganize(entry.Hash)
 }
 
 // GetBestHeight returns the height of the last block.
 func (bc *BlockChain) GetBestHeight() int {
-	var lastBlock types.Block
+	var tipEntry BlockIndexEntry
 	err := bc.db.View(func(tx *db_pkg.Tx) error {
 		b := tx.Bucket([]byte(utils.BLOCKS_BUCKET))
 
@@ -167,18 +278,15 @@ func (bc *BlockChain) GetBestHeight() int {
 			return errors.New("bc.GetBestHeight: last block hash does not exist")
 		}
 
-		// Get the last block from the database to retrieve its height.
-		blockData := b.Get(lastHash)
-		if blockData == nil {
-			return errors.New("bc.GetBestHeight: last block does not exist or last hash is invalid")
-		}
-		lastBlock = DeserializeBlock(blockData)
-		return nil
+		// The index entry holds the height, so the block is not loaded.
+		var err error
+		tipEntry, err = getBlockIndexEntry(tx.Bucket(utils.BLOCK_INDEX_BUCKET), lastHash)
+		return err
 	})
 	if err != nil {
 		log.Panic(err)
 	}
-	return lastBlock.Height
+	return tipEntry.Height
 }
 
 // GetBlock retrieves a block by given hash and deserialize it.
@@ -203,17 +311,48 @@ func (bc *BlockChain) GetBlock(blockHash []byte) (types.Block, error) {
 	*/
 }
 
+// AddBlockHeader validates given header and adds it to the block index
+// without block's transactions. Such a block is not connected until its
+// transactions are received with AddBlock.
+func (bc *BlockChain) AddBlockHeader(header types.BlockHeader) error {
+	vars.DBMutex.Lock()
+	defer vars.DBMutex.Unlock()
+	_, err := bc.GetBlockIndexEntry(header.Hash())
+	if err == nil {
+		return ErrBlockExists
+	}
+	if err != db_pkg.ErrKeyNotFound {
+		return err
+	}
+	err = bc.ValidateBlockHeader(header)
+	if err != nil {
+		return err
+	}
+	prevEntry, err := bc.GetBlockIndexEntry(header.PrevBlockHash)
+	if err != nil {
+		return err
+	}
+	entry := newBlockIndexEntry(header, &prevEntry)
+	return bc.db.Put(entry.Hash, entry.Serialize(), utils.BLOCK_INDEX_BUCKET, false)
+}
+
+// GetBlockHeader finds a block header by its hash without loading block's transactions.
+func (bc *BlockChain) GetBlockHeader(blockHash []byte) (types.BlockHeader, error) {
+	entry, err := bc.GetBlockIndexEntry(blockHash)
+	if err != nil {
+		return types.BlockHeader{}, err
+	}
+	return entry.Header, nil
+}
+
+// GetBlockHashes returns hashes of the blocks above given height,
+// ordered from the lowest block to the tip.
 func (bc *BlockChain) GetBlockHashes(height int) [][]byte {
-	var blocks [][]byte
-	bci := bc.Iterator()
-	for !bci.End() {
-		block := bci.Next()
-		if block.Height <= height {
-			break
-		}
-		blocks = append(blocks, block.Hash)
+	hashes, err := bc.GetBlockHashesInRange(height+1, math.MaxInt32)
+	if err != nil {
+		log.Panic(err)
 	}
-	return blocks
+	return hashes
 }
 
 func (bc *BlockChain) FindUTXO() map[string]tx_io.TXOutputs {
@@ -233,8 +372,11 @@ func (bc *BlockChain) FindUTXO() map[string]tx_io.TXOutputs {
 						}
 					}
 				}
-				outs := UTXO[txID]
-				outs.Outputs = append(outs.Outputs, out)
+				outs, ok := UTXO[txID]
+				if !ok {
+					outs = tx_io.TXOutputs{Outputs: make(map[int]tx_io.TXOutput)}
+				}
+				outs.Outputs[outIdx] = out
 				UTXO[txID] = outs
 			}
 			if tx.IsCoinBase() == false {
@@ -260,57 +402,62 @@ func (bc *BlockChain) Iterator() BlockChainIterator {
 	return BlockChainIterator{bc.tip, bc.db}
 }
 
-func NewUTXOTransaction(targetWallet *wallet.Wallet, to string, amount, fee float64, utxoSet *UTXOSet) types.Transaction {
-	var inputs []tx_io.TXInput
-	var outputs []tx_io.TXOutput
+func NewUTXOTransaction(targetWallet *wallet.Wallet, to string, amount, fee money.Amount, utxoSet *UTXOSet) types.Transaction {
 	pubKeyHash := wallet.HashPubKey(targetWallet.PublicKey)
-	acc, validOutputs := utxoSet.FindSpendableOutputs(pubKeyHash, amount, )
-	if acc < amount {
-		log.Panic("ERROR: Not enough funds")
+	from := fmt.Sprintf("%s", targetWallet.GetAddress())
+	tx := types.Transaction{
+		Version:   vars.TX_VERSION,
+		Hash:      nil,
+		Timestamp: time.Now().Unix(),
+		Fee:       0,
 	}
-	for txId, outs := range validOutputs {
-		prevTx, err := hex.DecodeString(txId)
+
+	// Fee depends on the number of inputs, so repeat the selection of outputs
+	// until they cover both the amount and the fee.
+	for {
+		total, err := money.Sum(amount, tx.Fee)
 		if err != nil {
 			log.Panic(err)
 		}
-		for _, out := range outs {
-			inputs = append(inputs, tx_io.TXInput{PreviousTx: prevTx, VOut: out, Signature: nil, PubKey: targetWallet.PublicKey})
+		acc, validOutputs := utxoSet.FindSpendableOutputs(pubKeyHash, total)
+		if acc < total {
+			log.Panic("ERROR: Not enough funds")
 		}
+		var inputs []tx_io.TXInput
+		for txId, outs := range validOutputs {
+			prevTx, err := hex.DecodeString(txId)
+			if err != nil {
+				log.Panic(err)
+			}
+			for _, out := range outs {
+				inputs = append(inputs, tx_io.TXInput{PreviousTx: prevTx, VOut: out, ScriptSig: nil, Sequence: vars.SEQUENCE_FINAL})
+			}
+		}
+		outputs := []tx_io.TXOutput{tx_io.NewTXOutput(amount, to)}
+		if acc > total {
+			outputs = append(outputs, tx_io.NewTXOutput(acc-total, from)) // a change
+		}
+		tx.VIn = inputs
+		tx.VOut = outputs
+		newFee, err := tx.CalculateFee(fee)
+		if err != nil {
+			log.Panic(err)
+		}
+		if newFee <= tx.Fee {
+			break
+		}
+		tx.Fee = newFee
 	}
-	from := fmt.Sprintf("%x", targetWallet.GetAddress())
-	outputs = append(outputs, tx_io.NewTXOutput(amount, to))
-	if acc > amount {
-		outputs = append(outputs, tx_io.NewTXOutput(acc-amount, from)) // a change
-	}
-	tx := types.Transaction{
-		Hash:      nil,
-		VIn:       inputs,
-		VOut:      outputs,
-		Timestamp: time.Now().Unix(),
-		Fee:       0,
-	}
-	tx.Hash = tx.CalcHash()
-	tx.Fee = tx.CalculateFee(fee)
-	return utxoSet.BlockChain.SignTransaction(tx, targetWallet.PrivateKey)
+	return utxoSet.BlockChain.SignTransaction(tx, targetWallet.PrivateKey, targetWallet.PublicKey)
 }
 
-// MineBlock generates new block.
-func (bc *BlockChain) MineBlock(minerAddress string, transactions []types.Transaction) (types.Block, error) {
+// MineBlock generates new block with given transactions and adds it to the chain.
+// Transactions which are not valid against the UTXO set or do not fit into
+// the block size and signature check limits are left out. Mining stops when
+// the context is cancelled.
+func (bc *BlockChain) MineBlock(ctx context.Context, minerAddress string, transactions []types.Transaction) (types.Block, error) {
 	var lastHash []byte
 	var lastHeight int
-	fees := 0.0
-
-	// Verify all given transactions
-	// If transaction is invalid, ignore it and send an error to its owner
-	for _, tx := range transactions {
-		if !bc.VerifyTransaction(tx) {
-
-			// TODO: send an error to transaction's author
-
-		} else {
-			fees += tx.Fee
-		}
-	}
 
 	// Retrieve last block height.
 	err := bc.db.View(func(tx *db_pkg.Tx) error {
@@ -319,8 +466,9 @@ func (bc *BlockChain) MineBlock(minerAddress string, transactions []types.Transa
 			return errors.New(fmt.Sprintf("bucket '%x' does not exist", utils.BLOCKS_BUCKET))
 		}
 
-		// Get a link to the last block.
-		lastHash = b.Get([]byte("l"))
+		// Get a link to the last block. Values are valid only until the
+		// end of the transaction, the hash is used after it.
+		lastHash = append([]byte{}, b.Get(utils.LAST_BLOCK_HASH)...)
 
 		// Get and deserialize the last block.
 		blockData := b.Get(lastHash)
@@ -332,41 +480,102 @@ func (bc *BlockChain) MineBlock(minerAddress string, transactions []types.Transa
 		log.Panic(err)
 	}
 
-	// Add coin base transaction to a list of transaction.
-	transactions = append(transactions, NewCoinBaseTX(minerAddress, fees))
-
-	// Generate new block.
-	newBlock, err := NewBlock(transactions, lastHash, lastHeight+1)
+	prevEntry, err := bc.GetBlockIndexEntry(lastHash)
 	if err != nil {
-		fmt.Println(err.Error())
-		return types.Block{}, err
+		log.Panic(err)
+	}
+	bits, err := bc.calcNextRequiredBits(prevEntry)
+	if err != nil {
+		log.Panic(err)
 	}
 
-	// Lock thread for safe database update.
-	vars.DBMutex.Lock()
-	err = bc.db.Batch(func(tx *db_pkg.Tx) error {
-		b := tx.Bucket(utils.BLOCKS_BUCKET)
-		if b == nil {
-			return errors.New(fmt.Sprintf("bucket '%x' does not exist", utils.BLOCKS_BUCKET))
+	// Verify all given transactions
+	// If transaction is invalid, ignore it and send an error to its owner
+	lockCtx, err := newLockContext(prevEntry, bc.GetBlockIndexEntry)
+	if err != nil {
+		log.Panic(err)
+	}
+	view := newUTXOView(UTXOSet{BlockChain: *bc}.FindCoin, lastHeight+1)
+	var blockTxs []types.Transaction
+	fees := money.Amount(0)
+
+	// Space for the header, the coin base and the transaction counter is
+	// reserved up front. Fees do not change the size of the coin base,
+	// the largest extra nonce is assumed.
+	coinBase := NewCoinBaseTX(minerAddress, lastHeight+1, 0)
+	setExtraNonce(&coinBase, lastHeight+1, math.MaxUint32)
+	blockSize := len(types.Block{Transactions: []types.Transaction{coinBase}, Height: lastHeight + 1}.Serialize()) +
+		wire.VarIntSize(uint64(len(transactions)+1))
+	sigOps := getLegacySigOpCount(coinBase)
+	for _, tx := range transactions {
+		txSize := len(tx.Serialize())
+		txSigOps := getTxSigOpCount(tx, view)
+		if blockSize+txSize > vars.MAX_BLOCK_SIZE || sigOps+txSigOps > vars.MAX_BLOCK_SIGOPS {
+			continue
 		}
-		err := b.Put(newBlock.Hash, newBlock.Serialize())
+		fee, err := checkTransaction(tx, view, lockCtx)
 		if err != nil {
-			log.Panic(err)
+
+			// TODO: send an error to transaction's author
+
+			continue
 		}
-		err = b.Put(utils.LAST_BLOCK_HASH, newBlock.Hash)
+		newFees, err := money.Sum(fees, fee)
 		if err != nil {
-			log.Panic(err)
+			continue
 		}
-		return nil
-	})
-	vars.DBMutex.Unlock()
+		view.addTransaction(tx)
+		blockTxs = append(blockTxs, tx)
+		fees = newFees
+		blockSize += txSize
+		sigOps += txSigOps
+	}
+
+	// Coin base transaction must be the first one in the block.
+	blockTxs = append([]types.Transaction{NewCoinBaseTX(minerAddress, lastHeight+1, fees)}, blockTxs...)
+
+	// The timestamp must be above median time past, adjusted time
+	// is within the allowed drift.
+	timestamp := GetAdjustedTime()
+	if timestamp <= lockCtx.medianTime {
+		timestamp = lockCtx.medianTime + 1
+	}
+
+	// Generate new block.
+	newBlock, err := NewBlock(ctx, blockTxs, lastHash, lastHeight+1, bits, timestamp)
 	if err != nil {
-		log.Panic(err)
+		fmt.Println(err.Error())
+		return types.Block{}, err
 	}
-	return bc.GetBlock(newBlock.Hash)
+	err = bc.AddBlock(newBlock)
+	if err != nil {
+		return types.Block{}, err
+	}
+	return newBlock, nil
 }
 
+// GenerateBlocks mines given number of blocks paying to the miner address
+// on top of the current tip and returns their hashes. Blocks contain only
+// the coin base, mining starts immediately and does not wait for peers.
+func (bc *BlockChain) GenerateBlocks(ctx context.Context, count int, minerAddress string) ([][]byte, error) {
+	var hashes [][]byte
+	for i := 0; i < count; i++ {
+		block, err := bc.MineBlock(ctx, minerAddress, nil)
+		if err != nil {
+			return hashes, err
+		}
+		hashes = append(hashes, block.Hash)
+	}
+	return hashes, nil
+}
+
+// FindTransaction finds a main chain transaction by its hash. The transaction
+// index is used if it is enabled, otherwise blocks are scanned from the tip.
 func (bc *BlockChain) FindTransaction(ID []byte) (types.Transaction, error) {
+	tx, _, err := bc.GetRawTransaction(ID)
+	if err != ErrTxIndexDisabled {
+		return tx, err
+	}
 	bci := bc.Iterator()
 	for !bci.End() {
 		block := bci.Next()
@@ -376,25 +585,34 @@ func (bc *BlockChain) FindTransaction(ID []byte) (types.Transaction, error) {
 			}
 		}
 	}
-	return types.Transaction{}, errors.New("transaction is not found")
+	return types.Transaction{}, ErrTxNotFound
 }
 
-func (bc *BlockChain) VerifyTransaction(tx types.Transaction) bool {
+// VerifyTransaction checks if given transaction can be included in the next
+// block. Inputs are checked against the UTXO set, so they must spend existing
+// unspent outputs. Returns the reason of rejection if the transaction is invalid.
+func (bc *BlockChain) VerifyTransaction(tx types.Transaction) error {
 	if tx.IsCoinBase() {
-		return true
+		return ErrLooseCoinBase
 	}
-	prevTXs := make(map[string]types.Transaction)
-	for _, vin := range tx.VIn {
-		prevTX, err := bc.FindTransaction(vin.PreviousTx)
-		if err != nil {
-			log.Panic(err)
-		}
-		prevTXs[hex.EncodeToString(prevTX.Hash)] = prevTX
+	tip, err := bc.db.Get(utils.LAST_BLOCK_HASH, utils.BLOCKS_BUCKET)
+	if err != nil {
+		return err
+	}
+	prevEntry, err := bc.GetBlockIndexEntry(tip)
+	if err != nil {
+		return err
+	}
+	ctx, err := newLockContext(prevEntry, bc.GetBlockIndexEntry)
+	if err != nil {
+		return err
 	}
-	return tx.Verify(prevTXs)
+	view := newUTXOView(UTXOSet{BlockChain: *bc}.FindCoin, ctx.height)
+	_, err = checkTransaction(tx, view, ctx)
+	return err
 }
 
-func (bc *BlockChain) SignTransaction(tx types.Transaction, privKey []byte) types.Transaction {
+func (bc *BlockChain) SignTransaction(tx types.Transaction, privKey, pubKey []byte) types.Transaction {
 	prevTXs := make(map[string]types.Transaction)
 	for _, vin := range tx.VIn {
 		prevTX, err := bc.FindTransaction(vin.PreviousTx)
@@ -403,7 +621,7 @@ func (bc *BlockChain) SignTransaction(tx types.Transaction, privKey []byte) type
 		}
 		prevTXs[hex.EncodeToString(prevTX.Hash)] = prevTX
 	}
-	return tx.Sign(privKey, prevTXs)
+	return tx.Sign(privKey, pubKey, prevTXs)
 }
 
 func (bc *BlockChain) CloseDB(Defer bool) {
@@ -15,8 +15,130 @@
 
 package core
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/config"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
 
 func Test(test *testing.T) {
 
 }
+
+func TestBlockChain_GenerateBlocks(test *testing.T) {
+	defer params.SetActive(params.Active())
+	params.SetActive(&params.RegTestParams)
+	dir, err := ioutil.TempDir("", "generate")
+	if err != nil {
+		test.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bc := CreateBlockChain(config.Config{ChainPath: filepath.Join(dir, "chain.db")})
+	defer bc.CloseDB(false)
+	utxoSet := UTXOSet{BlockChain: bc}
+	utxoSet.Reindex()
+
+	// Coin bases of blocks mined in the same second must not collide.
+	address := string(wallet.NewWallet().GetAddress())
+	hashes, err := bc.GenerateBlocks(context.Background(), 3, address)
+	if err != nil {
+		test.Fatal(err)
+	}
+	info, err := utxoSet.GetTxOutSetInfo()
+	if err != nil {
+		test.Fatal(err)
+	}
+	if len(hashes) != 3 || !bytes.Equal(hashes[2], info.BestBlock) {
+		test.Errorf("core.TestBlockChain_GenerateBlocks, tip:\nactual:\n%x\nexpected:\n%x", info.BestBlock, hashes)
+	}
+	if info.Height != 3 || info.Transactions != 3 || info.TotalAmount != GetTotalSubsidy(3)-GetBlockSubsidy(0) {
+		test.Errorf("core.TestBlockChain_GenerateBlocks, utxo set:\nactual:\n%+v\nexpected:\nheight 3, 3 transactions", info)
+	}
+}
+
+func TestBlockChain_AddBlockHeader(test *testing.T) {
+	defer params.SetActive(params.Active())
+	params.SetActive(&params.RegTestParams)
+	bc, cleanup := newTestGenesisChain(test)
+	defer cleanup()
+	genesis := bc.tip
+	address := string(wallet.NewWallet().GetAddress())
+	block := mineTestBlock(test, bc, genesis, address, 0)
+
+	// The header is stored without block's transactions.
+	err := bc.AddBlockHeader(block.BlockHeader)
+	if err != nil {
+		test.Fatal(err)
+	}
+	header, err := bc.GetBlockHeader(block.Hash)
+	if err != nil || !bytes.Equal(header.Serialize(), block.BlockHeader.Serialize()) {
+		test.Errorf("core.TestBlockChain_AddBlockHeader, stored header:\nactual:\n%+v, %v\nexpected:\n%+v", header, err, block.BlockHeader)
+	}
+	entry, err := bc.GetBlockIndexEntry(block.Hash)
+	if err != nil || entry.HaveData() || entry.Height != 1 {
+		test.Errorf("core.TestBlockChain_AddBlockHeader, index entry:\nactual:\n%+v, %v\nexpected:\nheight 1 without data", entry, err)
+	}
+
+	// A header with a hash above its target.
+	highHash := block.BlockHeader
+	for {
+		worker := NewProofOfWork(highHash)
+		if !worker.Validate() {
+			break
+		}
+		highHash.Nonce++
+	}
+
+	// Headers with wrong bits and with an unknown parent, which are mined
+	// to have valid proofs of work.
+	mineHeader := func(prevHash []byte, bits uint32) types.BlockHeader {
+		block, err := NewBlock(
+			context.Background(),
+			[]types.Transaction{NewCoinBaseTX(address, 1, 0)},
+			prevHash, 1, bits, block.Timestamp,
+		)
+		if err != nil {
+			test.Fatal(err)
+		}
+		return block.BlockHeader
+	}
+	data := []struct {
+		name     string
+		header   types.BlockHeader
+		expected error
+	}{
+		{"duplicate header", block.BlockHeader, ErrBlockExists},
+		{"hash above target", highHash, ErrHighHash},
+		{"wrong bits", mineHeader(genesis, block.Bits-1), ErrBadDiffBits},
+		{"unknown parent", mineHeader(bytes.Repeat([]byte{0xab}, 32), block.Bits), ErrPrevBlockNotFound},
+	}
+	for _, item := range data {
+		err := bc.AddBlockHeader(item.header)
+		if err != item.expected {
+			test.Errorf("core.TestBlockChain_AddBlockHeader, %s:\nactual:\n%v\nexpected:\n%v", item.name, err, item.expected)
+		}
+	}
+
+	// Transactions of a block with a stored header are added later.
+	err = bc.AddBlock(block)
+	if err != nil {
+		test.Fatal(err)
+	}
+	entry, err = bc.GetBlockIndexEntry(block.Hash)
+	if err != nil || !entry.HaveData() {
+		test.Errorf("core.TestBlockChain_AddBlockHeader, index entry after the block:\nactual:\n%+v, %v\nexpected:\nentry with data", entry, err)
+	}
+	tip, err := bc.db.Get(utils.LAST_BLOCK_HASH, utils.BLOCKS_BUCKET)
+	if err != nil || !bytes.Equal(tip, block.Hash) {
+		test.Errorf("core.TestBlockChain_AddBlockHeader, tip:\nactual:\n%x, %v\nexpected:\n%x", tip, err, block.Hash)
+	}
+}
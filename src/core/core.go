@@ -17,52 +17,103 @@ package core
 
 import (
 	"bytes"
-	"encoding/gob"
+	"context"
 	"log"
 	"time"
 
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
 )
 
-func NewBlock(transactions []types.Transaction, prevBlockHash []byte, height int) (types.Block, error) {
+// NewBlock creates a block with given transactions, the first of which
+// must be the coin base, and mines it using the configured number of
+// threads until a solution is found or the context is cancelled.
+func NewBlock(ctx context.Context, transactions []types.Transaction, prevBlockHash []byte, height int, bits uint32, timestamp int64) (types.Block, error) {
 	block := types.Block{
-		Timestamp:     time.Now().Unix(),
-		Transactions:  transactions,
-		PrevBlockHash: prevBlockHash,
-		Hash:          []byte{},
-		Nonce:         0,
-		Height:        height,
+		BlockHeader: types.BlockHeader{
+			Version:       vars.BLOCK_VERSION,
+			PrevBlockHash: prevBlockHash,
+			Timestamp:     timestamp,
+			Bits:          bits,
+			Nonce:         0,
+		},
+		Transactions: transactions,
+		Hash:         []byte{},
+		Height:       height,
 	}
-	worker := NewProofOfWork(block)
-	nonce, hash, err := worker.Run()
-	block.Hash = hash
-	block.Nonce = nonce
+	block.MerkleRoot = block.HashTransactions()
+	err := mineBlock(ctx, &block, miningThreads)
 	return block, err
 }
 
-func NewGenesisBlock(coinBase types.Transaction) (types.Block, error) {
-	return NewBlock([]types.Transaction{coinBase}, []byte{}, 0)
+// NewGenesisBlock creates the hardcoded first block of given network.
+// Its coin base carries the network's message and pays to an unspendable
+// script, the nonce is taken from the parameters instead of mining.
+func NewGenesisBlock(p *params.ChainParams) types.Block {
+	coinBase := types.Transaction{
+		Version:   vars.TX_VERSION,
+		VIn:       []tx_io.TXInput{{PreviousTx: []byte{}, VOut: -1, ScriptSig: []byte(p.GenesisMessage), Sequence: vars.SEQUENCE_FINAL}},
+		VOut:      []tx_io.TXOutput{{Value: money.Amount(p.Subsidy(0)), ScriptPubKey: []byte{script.OP_RETURN}}},
+		Timestamp: p.GenesisTimestamp,
+	}
+	coinBase.Hash = coinBase.CalcHash()
+	block := types.Block{
+		BlockHeader: types.BlockHeader{
+			Version:       vars.BLOCK_VERSION,
+			PrevBlockHash: []byte{},
+			Timestamp:     p.GenesisTimestamp,
+			Bits:          p.GenesisBits,
+			Nonce:         p.GenesisNonce,
+		},
+		Transactions: []types.Transaction{coinBase},
+		Height:       0,
+	}
+	block.MerkleRoot = block.HashTransactions()
+	block.Hash = block.BlockHeader.Hash()
+	return block
 }
 
 func DeserializeBlock(d []byte) types.Block {
-	var block types.Block
-	decoder := gob.NewDecoder(bytes.NewReader(d))
-	err := decoder.Decode(&block)
+	block, err := DecodeBlock(d)
 	if err != nil {
 		log.Panic(err)
 	}
 	return block
 }
 
-func NewCoinBaseTX(to string, fees float64) types.Transaction {
-	txIn := tx_io.TXInput{PreviousTx: []byte{}, VOut: -1, Signature: nil}
-	txOut := tx_io.NewTXOutput(vars.MINING_REWARD+fees, to)
+// DecodeBlock decodes a block received from a peer, which may be malformed.
+func DecodeBlock(data []byte) (types.Block, error) {
+	var block types.Block
+	r := bytes.NewReader(data)
+	err := block.Decode(r)
+	if err == nil && r.Len() != 0 {
+		err = wire.ErrTrailingData
+	}
+	return block, err
+}
+
+// NewCoinBaseTX creates a coin base transaction which pays the subsidy of
+// a block at given height and fees of block's transactions to given address.
+// The height is pushed to the input script, so coin bases of blocks mined
+// within the same second to the same address have different hashes.
+func NewCoinBaseTX(to string, height int, fees money.Amount) types.Transaction {
+	scriptSig, err := script.NewBuilder().AddInt64(int64(height)).Script()
+	if err != nil {
+		log.Panic(err)
+	}
+	txIn := tx_io.TXInput{PreviousTx: []byte{}, VOut: -1, ScriptSig: scriptSig, Sequence: vars.SEQUENCE_FINAL}
+	txOut := tx_io.NewTXOutput(GetBlockSubsidy(height)+fees, to)
 	tx := types.Transaction{
-		Hash:        nil,
+		Version:   vars.TX_VERSION,
+		Hash:      nil,
 		VIn:       []tx_io.TXInput{txIn},
 		VOut:      []tx_io.TXOutput{txOut},
+		LockTime:  0,
 		Timestamp: time.Now().Unix(),
 		Fee:       0,
 	}
@@ -71,11 +122,21 @@ func NewCoinBaseTX(to string, fees float64) types.Transaction {
 }
 
 func DeserializeTransaction(data []byte) types.Transaction {
-	var transaction types.Transaction
-	decoder := gob.NewDecoder(bytes.NewReader(data))
-	err := decoder.Decode(&transaction)
+	transaction, err := DecodeTransaction(data)
 	if err != nil {
 		log.Panic(err)
 	}
 	return transaction
 }
+
+// DecodeTransaction decodes a transaction received from a peer, which may
+// be malformed.
+func DecodeTransaction(data []byte) (types.Transaction, error) {
+	var transaction types.Transaction
+	r := bytes.NewReader(data)
+	err := transaction.Decode(r)
+	if err == nil && r.Len() != 0 {
+		err = wire.ErrTrailingData
+	}
+	return transaction, err
+}
@@ -16,18 +16,24 @@
 package core
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
 	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
 )
 
 func TestNewCoinBaseTX(test *testing.T) {
 	w := wallet.NewWallet()
-	coinBaseTx := NewCoinBaseTX(string(w.GetAddress()), 1.0567)
+	coinBaseTx := NewCoinBaseTX(string(w.GetAddress()), 0, 1056700)
 
 	if coinBaseTx.Fee != 0 {
-		test.Errorf("invalid coin base tx fee:\nactual:\n%f\nexpected:\n0", coinBaseTx.Fee)
+		test.Errorf("invalid coin base tx fee:\nactual:\n%s\nexpected:\n0", coinBaseTx.Fee)
 	}
 	if len(coinBaseTx.VIn) != 1 {
 		test.Errorf("invalid coin base tx inputs len:\nactual:\n%d\nexpected:\n1", len(coinBaseTx.VIn))
@@ -38,14 +44,21 @@ func TestNewCoinBaseTX(test *testing.T) {
 	if coinBaseTx.VIn[0].VOut != -1 {
 		test.Errorf("invalid coin base tx input out referance:\nactual:\n%d\nexpected:\n-1", coinBaseTx.VIn[0].VOut)
 	}
-	if coinBaseTx.VIn[0].Signature != nil {
-		test.Errorf("invalid coin base tx input signature:\nactual:\n%x\nexpected:\nnil", coinBaseTx.VIn[0].Signature)
+	if !bytes.Equal(coinBaseTx.VIn[0].ScriptSig, []byte{script.OP_0}) {
+		test.Errorf("invalid coin base tx input signature:\nactual:\n%x\nexpected:\n%x", coinBaseTx.VIn[0].ScriptSig, []byte{script.OP_0})
+	}
+	if coinBaseTx.LockTime != 0 {
+		test.Errorf("invalid coin base tx lock time:\nactual:\n%d\nexpected:\n0", coinBaseTx.LockTime)
+	}
+	expectedScriptSig := []byte{0x02, 0xf4, 0x01}
+	if scriptSig := NewCoinBaseTX(string(w.GetAddress()), 500, 0).VIn[0].ScriptSig; !bytes.Equal(scriptSig, expectedScriptSig) {
+		test.Errorf("invalid coin base tx input signature at height 500:\nactual:\n%x\nexpected:\n%x", scriptSig, expectedScriptSig)
 	}
 	if len(coinBaseTx.VOut) != 1 {
 		test.Errorf("invalid coin base tx outs len:\nactual:\n%d\nexpected:\n1", len(coinBaseTx.VOut))
 	}
-	if coinBaseTx.VOut[0].Value != vars.MINING_REWARD+1.0567 {
-		test.Errorf("invalid coin base tx output value:\nactual:\n%f\nexpected:\n%f", coinBaseTx.VOut[0].Value, vars.MINING_REWARD+1.0567)
+	if coinBaseTx.VOut[0].Value != vars.MINING_REWARD+1056700 {
+		test.Errorf("invalid coin base tx output value:\nactual:\n%s\nexpected:\n%s", coinBaseTx.VOut[0].Value, money.Amount(vars.MINING_REWARD+1056700))
 	}
 	expectedHash := coinBaseTx.CalcHash()
 	if len(coinBaseTx.Hash) != len(expectedHash) {
@@ -57,3 +70,22 @@ func TestNewCoinBaseTX(test *testing.T) {
 		}
 	}
 }
+
+func TestDecodeBlock_Malformed(test *testing.T) {
+	address := string(wallet.NewWallet().GetAddress())
+	tx := NewCoinBaseTX(address, 0, 0)
+	data := newTestBlock([]types.Transaction{tx}).Serialize()
+	if _, err := DecodeBlock(data); err != nil {
+		test.Errorf("core.TestDecodeBlock_Malformed, valid:\nactual:\n%v\nexpected:\n<nil>", err)
+	}
+	if _, err := DecodeBlock(data[:len(data)-1]); err == nil {
+		test.Errorf("core.TestDecodeBlock_Malformed, truncated:\nactual:\n<nil>\nexpected:\nerror")
+	}
+	if _, err := DecodeBlock(append(data, 0)); err != wire.ErrTrailingData {
+		test.Errorf("core.TestDecodeBlock_Malformed, trailing data:\nactual:\n%v\nexpected:\n%v", err, wire.ErrTrailingData)
+	}
+	txData := tx.Serialize()
+	if _, err := DecodeTransaction(txData[:len(txData)-1]); err == nil {
+		test.Errorf("core.TestDecodeBlock_Malformed, truncated tx:\nactual:\n<nil>\nexpected:\nerror")
+	}
+}
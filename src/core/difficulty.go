@@ -0,0 +1,48 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/YuriyLisovskiy/blockchain-go/src/consensus"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+// calcNextRequiredBits returns the compact target which a block following
+// given one must have. It is calculated using Dark Gravity Wave over the
+// branch the previous block belongs to, unless the active network keeps
+// the difficulty at the limit.
+func (bc *BlockChain) calcNextRequiredBits(prev BlockIndexEntry) (uint32, error) {
+	p := params.Active()
+	if p.PowNoRetargeting {
+		return p.PowLimitBits, nil
+	}
+	var prevBlocks []consensus.BlockInfo
+	entry := prev
+	for {
+		prevBlocks = append(prevBlocks, consensus.BlockInfo{Timestamp: entry.Header.Timestamp, Bits: entry.Header.Bits})
+		if len(prevBlocks) == p.DGWPastBlocks || entry.Height == 0 {
+			break
+		}
+		var err error
+		entry, err = bc.GetBlockIndexEntry(entry.Header.PrevBlockHash)
+		if err != nil {
+			return 0, err
+		}
+	}
+	powLimit := consensus.CompactToBig(p.PowLimitBits)
+	target := consensus.DarkGravityWave(prevBlocks, p.DGWPastBlocks, p.TargetSpacing, powLimit)
+	return consensus.BigToCompact(target), nil
+}
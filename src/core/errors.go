@@ -0,0 +1,65 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import "errors"
+
+// Block validation errors. The messages follow reject reasons used by
+// the reference client, so they can be passed to peers as is.
+var (
+	// Block structure errors.
+	ErrBlockExists        = errors.New("duplicate")
+	ErrNoTransactions     = errors.New("bad-blk-length")
+	ErrBlockTooLarge      = errors.New("bad-blk-length")
+	ErrTooManySigOps      = errors.New("bad-blk-sigops")
+	ErrFirstTxNotCoinBase = errors.New("bad-cb-missing")
+	ErrMultipleCoinBases  = errors.New("bad-cb-multiple")
+	ErrBadMerkleRoot      = errors.New("bad-txnmrklroot")
+	ErrDuplicateTx        = errors.New("bad-txns-duplicate")
+	ErrBadBlockHash       = errors.New("bad-blk-hash")
+	ErrHighHash           = errors.New("high-hash")
+	ErrBadDiffBits        = errors.New("bad-diffbits")
+	ErrTimeTooOld         = errors.New("time-too-old")
+	ErrTimeTooNew         = errors.New("time-too-new")
+
+	// Chain context errors.
+	ErrPrevBlockNotFound = errors.New("prev-blk-not-found")
+	ErrBadPrevBlock      = errors.New("bad-prevblk")
+	ErrBadHeight         = errors.New("bad-height")
+	ErrMissingUndoData   = errors.New("missing-undo-data")
+
+	// Transaction errors.
+	ErrNoTxInputs            = errors.New("bad-txns-vin-empty")
+	ErrNoTxOutputs           = errors.New("bad-txns-vout-empty")
+	ErrBadTxHash             = errors.New("bad-txns-hash")
+	ErrNegativeOutput        = errors.New("bad-txns-vout-negative")
+	ErrLargeOutput           = errors.New("bad-txns-vout-toolarge")
+	ErrLargeOutputTotal      = errors.New("bad-txns-txouttotal-toolarge")
+	ErrLooseCoinBase         = errors.New("coinbase")
+	ErrDuplicateInputs       = errors.New("bad-txns-inputs-duplicate")
+	ErrDoubleSpend           = errors.New("bad-txns-inputs-double-spend")
+	ErrMissingInputs         = errors.New("bad-txns-inputs-missingorspent")
+	ErrPrematureSpend        = errors.New("bad-txns-premature-spend-of-coinbase")
+	ErrBadSignature          = errors.New("bad-txns-signature")
+	ErrNonFinalTx            = errors.New("bad-txns-nonfinal")
+	ErrSequenceLocks         = errors.New("non-BIP68-final")
+	ErrInputValuesOutOfRange = errors.New("bad-txns-inputvalues-outofrange")
+	ErrInputsBelowOutputs    = errors.New("bad-txns-in-belowout")
+	ErrFeesOutOfRange        = errors.New("bad-txns-accumulated-fee-outofrange")
+	ErrBadCoinBaseAmount     = errors.New("bad-cb-amount")
+	ErrBadCoinBaseHeight     = errors.New("bad-cb-height")
+	ErrOverwriteTx           = errors.New("bad-txns-BIP30")
+)
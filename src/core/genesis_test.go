@@ -0,0 +1,35 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+func TestNewGenesisBlock(test *testing.T) {
+	for _, p := range []*params.ChainParams{&params.MainNetParams, &params.TestNetParams, &params.RegTestParams} {
+		genesis := NewGenesisBlock(p)
+		if !bytes.Equal(genesis.Hash, p.GenesisHash) {
+			test.Errorf("core.TestNewGenesisBlock, %s:\nactual:\n%x\nexpected:\n%x", p.Name, genesis.Hash, p.GenesisHash)
+		}
+		if err := checkBlockSanity(genesis); err != nil {
+			test.Errorf("core.TestNewGenesisBlock, %s:\nactual:\n%v\nexpected:\n%v", p.Name, err, nil)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+// heightKey encodes given height as a key of the height index. Keys are
+// big-endian, so the index is ordered by height.
+func heightKey(height int) []byte {
+	key := make([]byte, 4)
+	binary.BigEndian.PutUint32(key, uint32(height))
+	return key
+}
+
+// GetBlockHashByHeight returns the hash of the main chain block with given height.
+func (bc *BlockChain) GetBlockHashByHeight(height int) ([]byte, error) {
+	if height < 0 {
+		return nil, db_pkg.ErrKeyNotFound
+	}
+	return bc.db.Get(heightKey(height), utils.HEIGHT_INDEX_BUCKET)
+}
+
+// GetBlockByHeight retrieves the main chain block with given height.
+func (bc *BlockChain) GetBlockByHeight(height int) (types.Block, error) {
+	blockHash, err := bc.GetBlockHashByHeight(height)
+	if err != nil {
+		return types.Block{}, err
+	}
+	return bc.GetBlock(blockHash)
+}
+
+// GetBlockHashesInRange returns hashes of the main chain blocks with heights
+// from `from` to `to` inclusively, ordered by height. Heights above the tip
+// are skipped.
+func (bc *BlockChain) GetBlockHashesInRange(from, to int) ([][]byte, error) {
+	var hashes [][]byte
+	if from < 0 {
+		from = 0
+	}
+	if from > to {
+		return hashes, nil
+	}
+	err := bc.db.View(func(tx *db_pkg.Tx) error {
+		b := tx.Bucket(utils.HEIGHT_INDEX_BUCKET)
+		if b == nil {
+			return db_pkg.ErrBucketNotFound
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(heightKey(from)); k != nil; k, v = c.Next() {
+			if int(binary.BigEndian.Uint32(k)) > to {
+				break
+			}
+			hashes = append(hashes, append([]byte{}, v...))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
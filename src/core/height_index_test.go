@@ -0,0 +1,75 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+)
+
+func TestHeightIndex(test *testing.T) {
+	bc, cleanup := newTestBlockChain(test, vars.UTXO_BUCKET)
+	defer cleanup()
+	utxoSet := UTXOSet{BlockChain: bc}
+	coinBase := []tx_io.TXInput{{PreviousTx: []byte{}, VOut: -1}}
+	var blocks []types.Block
+	for height := 0; height < 4; height++ {
+		block := types.Block{
+			Hash:         []byte(fmt.Sprintf("block%d", height)),
+			Height:       height,
+			Transactions: []types.Transaction{newTestTx(fmt.Sprintf("cb%d", height), coinBase, 50)},
+		}
+		utxoSet.Update(block)
+		blocks = append(blocks, block)
+	}
+
+	data := []struct {
+		from, to int
+		expected [][]byte
+	}{
+		{0, 3, [][]byte{blocks[0].Hash, blocks[1].Hash, blocks[2].Hash, blocks[3].Hash}},
+		{1, 2, [][]byte{blocks[1].Hash, blocks[2].Hash}},
+		{2, 100, [][]byte{blocks[2].Hash, blocks[3].Hash}},
+		{-5, 0, [][]byte{blocks[0].Hash}},
+		{3, 1, nil},
+		{10, 20, nil},
+	}
+	for _, d := range data {
+		actual, err := bc.GetBlockHashesInRange(d.from, d.to)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, d.expected) {
+			test.Errorf("core.TestHeightIndex[%d, %d]:\nactual:\n%s\nexpected:\n%s", d.from, d.to, actual, d.expected)
+		}
+	}
+
+	// Disconnected blocks are removed from the index.
+	utxoSet.Disconnect(blocks[3])
+	if _, err := bc.GetBlockHashByHeight(3); err != db_pkg.ErrKeyNotFound {
+		test.Errorf("core.TestHeightIndex:\nactual:\n%v\nexpected:\n%v", err, db_pkg.ErrKeyNotFound)
+	}
+	actual, err := bc.GetBlockHashByHeight(2)
+	if err != nil || string(actual) != string(blocks[2].Hash) {
+		test.Errorf("core.TestHeightIndex:\nactual:\n%s, %v\nexpected:\n%s", actual, err, blocks[2].Hash)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sort"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+)
+
+// lockContext describes the block transactions are checked for, which is
+// required to check their lock times and relative lock times of their inputs.
+type lockContext struct {
+	height     int   // height of the block
+	medianTime int64 // median time past of the previous block
+	prev       BlockIndexEntry
+	lookup     func(blockHash []byte) (BlockIndexEntry, error)
+}
+
+// newLockContext creates a context of the block following given one.
+// lookup retrieves index entries of ancestors of the block.
+func newLockContext(prev BlockIndexEntry, lookup func(blockHash []byte) (BlockIndexEntry, error)) (lockContext, error) {
+	medianTime, err := medianTimePast(prev, lookup)
+	if err != nil {
+		return lockContext{}, err
+	}
+	return lockContext{
+		height:     prev.Height + 1,
+		medianTime: medianTime,
+		prev:       prev,
+		lookup:     lookup,
+	}, nil
+}
+
+// medianTimeAt returns median time past of the block with given height
+// which is an ancestor of the previous block.
+func (c lockContext) medianTimeAt(height int) (int64, error) {
+	entry := c.prev
+	for entry.Height > height {
+		var err error
+		entry, err = c.lookup(entry.Header.PrevBlockHash)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return medianTimePast(entry, c.lookup)
+}
+
+// medianTimePast returns the median of timestamps of given block and up to
+// vars.MEDIAN_TIME_SPAN-1 of its ancestors.
+func medianTimePast(entry BlockIndexEntry, lookup func(blockHash []byte) (BlockIndexEntry, error)) (int64, error) {
+	var timestamps []int64
+	for {
+		timestamps = append(timestamps, entry.Header.Timestamp)
+		if len(timestamps) == vars.MEDIAN_TIME_SPAN || entry.Height == 0 {
+			break
+		}
+		var err error
+		entry, err = lookup(entry.Header.PrevBlockHash)
+		if err != nil {
+			return 0, err
+		}
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i] < timestamps[j]
+	})
+	return timestamps[len(timestamps)/2], nil
+}
+
+// calcSequenceLock returns the last block height and median time past at
+// which the transaction is still locked by relative lock times of its inputs,
+// -1 means there is no lock of the kind. coinHeights holds heights of outputs
+// spent by the inputs. Relative lock times apply to transactions of version 2
+// and above.
+func calcSequenceLock(tx types.Transaction, coinHeights []int, c lockContext) (int, int64, error) {
+	minHeight, minTime := -1, int64(-1)
+	if tx.Version < 2 || tx.IsCoinBase() {
+		return minHeight, minTime, nil
+	}
+	for i, vin := range tx.VIn {
+		if vin.Sequence&vars.SEQUENCE_LOCKTIME_DISABLE_FLAG != 0 {
+			continue
+		}
+		lock := int64(vin.Sequence & vars.SEQUENCE_LOCKTIME_MASK)
+		if vin.Sequence&vars.SEQUENCE_LOCKTIME_TYPE_FLAG == 0 {
+			if height := coinHeights[i] + int(lock) - 1; height > minHeight {
+				minHeight = height
+			}
+			continue
+		}
+
+		// Time based locks start at median time past of the block
+		// preceding the one which includes the spent output.
+		prevHeight := coinHeights[i] - 1
+		if prevHeight < 0 {
+			prevHeight = 0
+		}
+		coinTime, err := c.medianTimeAt(prevHeight)
+		if err != nil {
+			return 0, 0, err
+		}
+		if t := coinTime + lock<<vars.SEQUENCE_LOCKTIME_GRANULARITY - 1; t > minTime {
+			minTime = t
+		}
+	}
+	return minHeight, minTime, nil
+}
+
+// checkTxLocks checks that lock time of the transaction and relative lock
+// times of its inputs allow to include it in the block of given context.
+func checkTxLocks(tx types.Transaction, coinHeights []int, c lockContext) error {
+	if !tx.IsFinal(c.height, c.medianTime) {
+		return ErrNonFinalTx
+	}
+	minHeight, minTime, err := calcSequenceLock(tx, coinHeights, c)
+	if err != nil {
+		return err
+	}
+	if minHeight >= c.height || minTime >= c.medianTime {
+		return ErrSequenceLocks
+	}
+	return nil
+}
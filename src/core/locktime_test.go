@@ -0,0 +1,128 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+)
+
+// newTestChain creates index entries of a chain with given block timestamps
+// and a function which looks them up.
+func newTestChain(timestamps ...int64) ([]BlockIndexEntry, func([]byte) (BlockIndexEntry, error)) {
+	var entries []BlockIndexEntry
+	index := make(map[string]BlockIndexEntry)
+	prevHash := []byte{}
+	for height, timestamp := range timestamps {
+		entry := BlockIndexEntry{
+			Hash:   []byte(fmt.Sprintf("block%d", height)),
+			Header: types.BlockHeader{PrevBlockHash: prevHash, Timestamp: timestamp},
+			Height: height,
+		}
+		index[string(entry.Hash)] = entry
+		entries = append(entries, entry)
+		prevHash = entry.Hash
+	}
+	return entries, func(blockHash []byte) (BlockIndexEntry, error) {
+		entry, ok := index[string(blockHash)]
+		if !ok {
+			return BlockIndexEntry{}, errors.New("block is not indexed")
+		}
+		return entry, nil
+	}
+}
+
+func TestMedianTimePast(test *testing.T) {
+	entries, lookup := newTestChain(10, 30, 20, 50, 40, 60, 70, 90, 80, 100, 110, 120)
+	data := []struct {
+		height   int
+		expected int64
+	}{
+		{0, 10},
+		{1, 30},
+		{2, 20},
+		{4, 30},
+		{10, 60},
+		{11, 70},
+	}
+	for _, d := range data {
+		actual, err := medianTimePast(entries[d.height], lookup)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if actual != d.expected {
+			test.Errorf("core.TestMedianTimePast[%d]:\nactual:\n%d\nexpected:\n%d", d.height, actual, d.expected)
+		}
+	}
+}
+
+func TestCheckTxLocks(test *testing.T) {
+	var timestamps []int64
+	for i := 0; i < 30; i++ {
+		timestamps = append(timestamps, 1536000000+int64(i)*1000)
+	}
+	entries, lookup := newTestChain(timestamps...)
+
+	// The block being checked has height 30.
+	ctx, err := newLockContext(entries[29], lookup)
+	if err != nil {
+		test.Fatal(err)
+	}
+	newTx := func(lockTime uint32, sequence uint32) types.Transaction {
+		return types.Transaction{
+			Version:  2,
+			VIn:      []tx_io.TXInput{{PreviousTx: []byte("prev"), Sequence: sequence}},
+			LockTime: lockTime,
+		}
+	}
+	timeLock := func(seconds uint32) uint32 {
+		return vars.SEQUENCE_LOCKTIME_TYPE_FLAG | seconds>>vars.SEQUENCE_LOCKTIME_GRANULARITY
+	}
+
+	// Median time past of the block 29 is 1536024000, the output spent
+	// in time based cases is included in the block 20, median time past
+	// of the block 19 is 1536014000.
+	data := []struct {
+		name       string
+		tx         types.Transaction
+		coinHeight int
+		expected   error
+	}{
+		{"final", newTx(0, vars.SEQUENCE_FINAL), 20, nil},
+		{"height lock time reached", newTx(29, 0), 20, nil},
+		{"height lock time not reached", newTx(30, 0), 20, ErrNonFinalTx},
+		{"time lock time reached", newTx(1536023999, 0), 20, nil},
+		{"time lock time not reached", newTx(1536024000, 0), 20, ErrNonFinalTx},
+		{"lock time of final inputs", newTx(30, vars.SEQUENCE_FINAL), 20, nil},
+		{"relative height reached", newTx(0, 10), 20, nil},
+		{"relative height not reached", newTx(0, 11), 20, ErrSequenceLocks},
+		{"relative height disabled", newTx(0, vars.SEQUENCE_LOCKTIME_DISABLE_FLAG|11), 20, nil},
+		{"relative time reached", newTx(0, timeLock(9728)), 20, nil},
+		{"relative time not reached", newTx(0, timeLock(10240)), 20, ErrSequenceLocks},
+		{"relative lock of version 1", types.Transaction{Version: 1, VIn: []tx_io.TXInput{{PreviousTx: []byte("prev"), Sequence: 11}}}, 20, nil},
+	}
+	for _, d := range data {
+		actual := checkTxLocks(d.tx, []int{d.coinHeight}, ctx)
+		if actual != d.expected {
+			test.Errorf("core.TestCheckTxLocks, %s:\nactual:\n%v\nexpected:\n%v", d.name, actual, d.expected)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/consensus"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+// ErrResyncRequired is returned for databases which can not be converted
+// to the current storage format.
+var ErrResyncRequired = errors.New("database is written by an incompatible version of the node, re-sync required")
+
+// GOB_TARGET_BITS is the fixed difficulty of blocks stored with gob, their
+// hashes were below 2^(256-GOB_TARGET_BITS).
+const GOB_TARGET_BITS = 16
+
+// Blocks, transactions and outputs as they were stored with gob before
+// databases got a version record.
+type gobBlock struct {
+	Timestamp     int64
+	Transactions  []gobTransaction
+	PrevBlockHash []byte
+	Hash          []byte
+	Nonce         int
+	Height        int
+}
+
+type gobTransaction struct {
+	Hash      []byte
+	VIn       []gobTXInput
+	VOut      []gobTXOutput
+	Timestamp int64
+	Fee       float64
+}
+
+type gobTXInput struct {
+	PreviousTx []byte
+	VOut       int
+	Signature  []byte
+	PubKey     []byte
+}
+
+type gobTXOutput struct {
+	Value      float64
+	PubKeyHash []byte
+}
+
+func encodeDBVersion(version uint32) []byte {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, version)
+	return data
+}
+
+// migrateDB converts a database written by an older version of the node
+// to the current storage format. Databases without a version record store
+// blocks encoded with gob, which are converted by migrateGobBlocks. Blocks
+// of version 1 may hold inputs of two layouts which can not be told apart,
+// so ErrResyncRequired is returned for them. Blocks of version 2 are stored
+// in the current format, only unspent outputs and undo records, which got
+// coin base flags in version 3, are rebuilt from blocks.
+func (bc *BlockChain) migrateDB() error {
+	version := uint32(0)
+	data, err := bc.db.Get(utils.DB_VERSION, utils.BLOCKS_BUCKET)
+	if err == nil && len(data) == 4 {
+		version = binary.LittleEndian.Uint32(data)
+	} else if err != nil && err != db_pkg.ErrKeyNotFound {
+		return err
+	}
+	if version == vars.DB_VERSION {
+		return nil
+	}
+	if version == 1 {
+		return ErrResyncRequired
+	}
+	if version > vars.DB_VERSION {
+		return errors.New(fmt.Sprintf("database version %d is not supported", version))
+	}
+	return bc.db.Update(func(tx *db_pkg.Tx) error {
+		if version < 1 {
+			err := bc.migrateGobBlocks(tx)
+			if err != nil {
+				return err
+			}
+		}
+		err := resetChainState(tx)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(utils.BLOCKS_BUCKET).Put(utils.DB_VERSION, encodeDBVersion(vars.DB_VERSION))
+	})
+}
+
+// resetChainState removes unspent outputs and undo records, so they are
+// rebuilt from blocks in the current format.
+func resetChainState(tx *db_pkg.Tx) error {
+	for _, bucket := range [][]byte{vars.UTXO_BUCKET, vars.UNDO_BUCKET} {
+		err := tx.DeleteBucket(bucket)
+		if err != nil && err != db_pkg.ErrBucketNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateGobBlocks converts blocks of the main chain stored with gob to the
+// current format. Values in coins become amounts, public key hashes become
+// pay-to-pubkey-hash scripts and signatures with public keys become input
+// scripts. Hashes of transactions and blocks are recomputed, so references
+// to previous transactions and blocks are rewritten. Signatures and proofs
+// of work are not valid for the new hashes, the converted chain is only
+// re-indexed, it is not validated again. Side branches are dropped, the block
+// index and optional indexes are removed to be rebuilt.
+func (bc *BlockChain) migrateGobBlocks(tx *db_pkg.Tx) error {
+	b := tx.Bucket(utils.BLOCKS_BUCKET)
+	var chain []gobBlock
+	for hash := b.Get(utils.LAST_BLOCK_HASH); len(hash) > 0; {
+		data := b.Get(hash)
+		if data == nil {
+			return errors.New(fmt.Sprintf("block %x is not found", hash))
+		}
+		var block gobBlock
+		err := gob.NewDecoder(bytes.NewReader(data)).Decode(&block)
+		if err != nil {
+			return err
+		}
+		chain = append([]gobBlock{block}, chain...)
+		hash = block.PrevBlockHash
+	}
+	if len(chain) == 0 {
+		return errors.New("database has no blocks")
+	}
+	bits := consensus.BigToCompact(new(big.Int).Lsh(big.NewInt(1), 256-GOB_TARGET_BITS))
+	txHashes := make(map[string][]byte)
+	var blocks []types.Block
+	prevHash := []byte{}
+	for _, oldBlock := range chain {
+		block := types.Block{Height: oldBlock.Height}
+		for _, oldTx := range oldBlock.Transactions {
+			newTx, err := convertGobTransaction(oldTx, txHashes)
+			if err != nil {
+				return err
+			}
+			txHashes[hex.EncodeToString(oldTx.Hash)] = newTx.Hash
+			block.Transactions = append(block.Transactions, newTx)
+		}
+		block.BlockHeader = types.BlockHeader{
+			Version:       vars.BLOCK_VERSION,
+			PrevBlockHash: prevHash,
+			Timestamp:     oldBlock.Timestamp,
+			Bits:          bits,
+			Nonce:         uint32(oldBlock.Nonce),
+		}
+		block.MerkleRoot = block.HashTransactions()
+		block.Hash = block.BlockHeader.Hash()
+		blocks = append(blocks, block)
+		prevHash = block.Hash
+	}
+	buckets := [][]byte{
+		utils.BLOCKS_BUCKET,
+		utils.BLOCK_INDEX_BUCKET,
+		utils.HEIGHT_INDEX_BUCKET,
+		utils.TX_INDEX_BUCKET,
+		utils.ADDR_INDEX_BUCKET,
+	}
+	for _, bucket := range buckets {
+		err := tx.DeleteBucket(bucket)
+		if err != nil && err != db_pkg.ErrBucketNotFound {
+			return err
+		}
+	}
+	b, err := tx.CreateBucket(utils.BLOCKS_BUCKET)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		err = b.Put(block.Hash, block.Serialize())
+		if err != nil {
+			return err
+		}
+	}
+	bc.tip = prevHash
+	return b.Put(utils.LAST_BLOCK_HASH, prevHash)
+}
+
+// convertGobTransaction converts a transaction stored with gob. txHashes maps
+// old hashes of converted transactions to the new ones.
+func convertGobTransaction(oldTx gobTransaction, txHashes map[string][]byte) (types.Transaction, error) {
+	tx := types.Transaction{
+		Version:   vars.TX_VERSION,
+		Timestamp: oldTx.Timestamp,
+		Fee:       coinsToAmount(oldTx.Fee),
+	}
+	for _, oldIn := range oldTx.VIn {
+		in := tx_io.TXInput{PreviousTx: []byte{}, VOut: oldIn.VOut, Sequence: vars.SEQUENCE_FINAL}
+		if len(oldIn.PreviousTx) == 0 {
+
+			// Coin bases kept arbitrary data in place of the public key.
+			in.ScriptSig = oldIn.PubKey
+		} else {
+			prevHash, ok := txHashes[hex.EncodeToString(oldIn.PreviousTx)]
+			if !ok {
+				return tx, errors.New(fmt.Sprintf("transaction %x spends unknown transaction %x", oldTx.Hash, oldIn.PreviousTx))
+			}
+			in.PreviousTx = prevHash
+			scriptSig, err := script.NewBuilder().AddData(oldIn.Signature).AddData(oldIn.PubKey).Script()
+			if err != nil {
+				return tx, err
+			}
+			in.ScriptSig = scriptSig
+		}
+		tx.VIn = append(tx.VIn, in)
+	}
+	for _, oldOut := range oldTx.VOut {
+		scriptPubKey, err := script.PayToPubKeyHashScript(oldOut.PubKeyHash)
+		if err != nil {
+			return tx, err
+		}
+		tx.VOut = append(tx.VOut, tx_io.TXOutput{Value: coinsToAmount(oldOut.Value), ScriptPubKey: scriptPubKey})
+	}
+	tx.Hash = tx.CalcHash()
+	return tx, nil
+}
+
+// coinsToAmount converts a value in coins stored as a float to an amount.
+func coinsToAmount(value float64) money.Amount {
+	return money.Amount(math.Round(value * vars.COIN))
+}
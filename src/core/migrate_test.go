@@ -0,0 +1,324 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/config"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+// Blocks, transactions and outputs as they were stored with gob before
+// databases got a version record.
+type legacyBlock struct {
+	Timestamp     int64
+	Transactions  []legacyTransaction
+	PrevBlockHash []byte
+	Hash          []byte
+	Nonce         int
+	Height        int
+}
+
+type legacyTransaction struct {
+	Hash      []byte
+	VIn       []legacyTXInput
+	VOut      []legacyTXOutput
+	Timestamp int64
+	Fee       float64
+}
+
+type legacyTXInput struct {
+	PreviousTx []byte
+	VOut       int
+	Signature  []byte
+	PubKey     []byte
+}
+
+type legacyTXOutput struct {
+	Value      float64
+	PubKeyHash []byte
+}
+
+func TestMigrateDB_GobBlocks(test *testing.T) {
+	bc, cleanup := newTestBlockChain(test)
+	defer cleanup()
+	db := bc.db
+
+	alice, bob := bytes.Repeat([]byte{0x01}, 20), bytes.Repeat([]byte{0x02}, 20)
+	genesis := legacyBlock{
+		Timestamp: 1536000000,
+		Transactions: []legacyTransaction{{
+			Hash:      []byte("coinbase0"),
+			VIn:       []legacyTXInput{{PreviousTx: []byte{}, VOut: -1, PubKey: []byte("genesis")}},
+			VOut:      []legacyTXOutput{{Value: 10.5, PubKeyHash: alice}},
+			Timestamp: 1536000000,
+		}},
+		PrevBlockHash: []byte{},
+		Hash:          []byte("legacy block 0"),
+		Nonce:         42,
+	}
+	block := legacyBlock{
+		Timestamp: 1536000600,
+		Transactions: []legacyTransaction{
+			{
+				Hash:      []byte("coinbase1"),
+				VIn:       []legacyTXInput{{PreviousTx: []byte{}, VOut: -1, PubKey: []byte("block 1")}},
+				VOut:      []legacyTXOutput{{Value: 10, PubKeyHash: bob}},
+				Timestamp: 1536000600,
+			},
+			{
+				Hash:      []byte("spend"),
+				VIn:       []legacyTXInput{{PreviousTx: []byte("coinbase0"), VOut: 0, Signature: []byte("signature"), PubKey: []byte("pubkey")}},
+				VOut:      []legacyTXOutput{{Value: 10.499999, PubKeyHash: bob}},
+				Timestamp: 1536000500,
+				Fee:       0.000001,
+			},
+		},
+		PrevBlockHash: genesis.Hash,
+		Hash:          []byte("legacy block 1"),
+		Nonce:         7,
+		Height:        1,
+	}
+	for _, legacy := range []legacyBlock{genesis, block} {
+		var gobData bytes.Buffer
+		err := gob.NewEncoder(&gobData).Encode(legacy)
+		if err != nil {
+			test.Fatal(err)
+		}
+		err = db.Put(legacy.Hash, gobData.Bytes(), utils.BLOCKS_BUCKET, false)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+	err := db.Put(utils.LAST_BLOCK_HASH, block.Hash, utils.BLOCKS_BUCKET, false)
+	if err != nil {
+		test.Fatal(err)
+	}
+	bc.tip = block.Hash
+	if err := bc.migrateDB(); err != nil {
+		test.Fatal(err)
+	}
+
+	// Blocks are stored by their new hashes in the current format.
+	for _, legacy := range []legacyBlock{genesis, block} {
+		if _, err := db.Get(legacy.Hash, utils.BLOCKS_BUCKET); err != db_pkg.ErrKeyNotFound {
+			test.Errorf("core.TestMigrateDB_GobBlocks: gob block %s:\nactual:\n%v\nexpected:\n%v", legacy.Hash, err, db_pkg.ErrKeyNotFound)
+		}
+	}
+	tip, err := db.Get(utils.LAST_BLOCK_HASH, utils.BLOCKS_BUCKET)
+	if err != nil || !bytes.Equal(tip, bc.tip) {
+		test.Fatalf("core.TestMigrateDB_GobBlocks, tip:\nactual:\n%x, %v\nexpected:\n%x", tip, err, bc.tip)
+	}
+	data, err := db.Get(tip, utils.BLOCKS_BUCKET)
+	if err != nil {
+		test.Fatal(err)
+	}
+	newBlock := DeserializeBlock(data)
+	data, err = db.Get(newBlock.PrevBlockHash, utils.BLOCKS_BUCKET)
+	if err != nil {
+		test.Fatal(err)
+	}
+	newGenesis := DeserializeBlock(data)
+	if newBlock.Height != 1 || newBlock.Nonce != 7 || !bytes.Equal(newBlock.MerkleRoot, newBlock.HashTransactions()) {
+		test.Errorf("core.TestMigrateDB_GobBlocks, block:\nactual:\n%+v\nexpected:\nheight 1, nonce 7", newBlock.BlockHeader)
+	}
+	coinBase, spend := newGenesis.Transactions[0], newBlock.Transactions[1]
+	expectedScriptSig, _ := script.NewBuilder().AddData([]byte("signature")).AddData([]byte("pubkey")).Script()
+	expectedScriptPubKey, _ := script.PayToPubKeyHashScript(bob)
+	if !bytes.Equal(coinBase.VIn[0].ScriptSig, []byte("genesis")) || coinBase.VOut[0].Value != 10500000 {
+		test.Errorf("core.TestMigrateDB_GobBlocks, coin base:\nactual:\n%+v\nexpected:\ngenesis data paying 10.5", coinBase)
+	}
+	if !bytes.Equal(spend.VIn[0].PreviousTx, coinBase.Hash) || !bytes.Equal(spend.VIn[0].ScriptSig, expectedScriptSig) {
+		test.Errorf("core.TestMigrateDB_GobBlocks, input:\nactual:\n%+v\nexpected:\n%x spent with %x", spend.VIn[0], coinBase.Hash, expectedScriptSig)
+	}
+	if spend.VOut[0].Value != 10499999 || spend.Fee != 1 || !bytes.Equal(spend.VOut[0].ScriptPubKey, expectedScriptPubKey) {
+		test.Errorf("core.TestMigrateDB_GobBlocks, output:\nactual:\n%+v, fee %d\nexpected:\n10.499999 to %x, fee 1", spend.VOut[0], spend.Fee, expectedScriptPubKey)
+	}
+
+	// The chain state is rebuilt from converted blocks.
+	bc.initBlockIndex()
+	utxoSet := UTXOSet{BlockChain: bc}
+	utxoSet.Reindex()
+	if _, ok := utxoSet.FindCoin(coinBase.Hash, 0); ok {
+		test.Errorf("core.TestMigrateDB_GobBlocks: spent output %x:0 is unspent", coinBase.Hash)
+	}
+	if _, ok := utxoSet.FindCoin(spend.Hash, 0); !ok {
+		test.Errorf("core.TestMigrateDB_GobBlocks: output %x:0 is not unspent", spend.Hash)
+	}
+	version, err := db.Get(utils.DB_VERSION, utils.BLOCKS_BUCKET)
+	if err != nil || !bytes.Equal(version, encodeDBVersion(vars.DB_VERSION)) {
+		test.Errorf("core.TestMigrateDB_GobBlocks: version:\nactual:\n%x, %v\nexpected:\n%x", version, err, encodeDBVersion(vars.DB_VERSION))
+	}
+}
+
+// encodeVersion1Block encodes a block with a single coin base in the layout
+// of version 1, where inputs hold a signature and a public key and neither
+// inputs have sequences nor transactions have lock times.
+func encodeVersion1Block(test *testing.T, header types.BlockHeader, coinBase legacyTransaction) []byte {
+	var buff bytes.Buffer
+	buff.Write(header.Serialize())
+	write := func(err error) {
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+	write(wire.WriteVarInt(&buff, 0))
+	write(wire.WriteVarInt(&buff, 1))
+	write(wire.WriteUint32(&buff, 1))
+	write(wire.WriteVarBytes(&buff, coinBase.Hash))
+	write(wire.WriteVarInt(&buff, uint64(len(coinBase.VIn))))
+	for _, in := range coinBase.VIn {
+		write(wire.WriteVarBytes(&buff, in.PreviousTx))
+		write(wire.WriteUint32(&buff, uint32(int32(in.VOut))))
+		write(wire.WriteVarBytes(&buff, in.Signature))
+		write(wire.WriteVarBytes(&buff, in.PubKey))
+	}
+	write(wire.WriteVarInt(&buff, uint64(len(coinBase.VOut))))
+	for _, out := range coinBase.VOut {
+		write(wire.WriteUint64(&buff, uint64(coinsToAmount(out.Value))))
+		write(wire.WriteVarBytes(&buff, out.PubKeyHash))
+	}
+	write(wire.WriteUint64(&buff, uint64(coinBase.Timestamp)))
+	write(wire.WriteUint64(&buff, 0))
+	return buff.Bytes()
+}
+
+func TestMigrateDB_Version1(test *testing.T) {
+	bc, cleanup := newTestBlockChain(test)
+	defer cleanup()
+	db := bc.db
+
+	header := types.BlockHeader{
+		Version:       vars.BLOCK_VERSION,
+		PrevBlockHash: []byte{},
+		MerkleRoot:    bytes.Repeat([]byte{0x01}, 32),
+		Timestamp:     1536000000,
+		Bits:          0x207fffff,
+	}
+	data := encodeVersion1Block(test, header, legacyTransaction{
+		Hash:      []byte("coinbase"),
+		VIn:       []legacyTXInput{{PreviousTx: []byte{}, VOut: -1, PubKey: []byte("genesis")}},
+		VOut:      []legacyTXOutput{{Value: 50, PubKeyHash: bytes.Repeat([]byte{0x02}, 20)}},
+		Timestamp: 1536000000,
+	})
+	if _, err := DecodeBlock(data); err == nil {
+		test.Errorf("core.TestMigrateDB_Version1: version 1 block is decoded in the current format")
+	}
+	hash := header.Hash()
+	err := db.PutArray(
+		[][]byte{hash, utils.LAST_BLOCK_HASH, utils.DB_VERSION},
+		[][]byte{data, hash, encodeDBVersion(1)},
+		utils.BLOCKS_BUCKET, false,
+	)
+	if err != nil {
+		test.Fatal(err)
+	}
+	bc.tip = hash
+	if err := bc.migrateDB(); err != ErrResyncRequired {
+		test.Errorf("core.TestMigrateDB_Version1:\nactual:\n%v\nexpected:\n%v", err, ErrResyncRequired)
+	}
+
+	// The database is left untouched.
+	stored, err := db.Get(hash, utils.BLOCKS_BUCKET)
+	if err != nil || !bytes.Equal(stored, data) {
+		test.Errorf("core.TestMigrateDB_Version1: block is changed:\nactual:\n%x\nexpected:\n%x", stored, data)
+	}
+	version, err := db.Get(utils.DB_VERSION, utils.BLOCKS_BUCKET)
+	if err != nil || !bytes.Equal(version, encodeDBVersion(1)) {
+		test.Errorf("core.TestMigrateDB_Version1: version:\nactual:\n%x, %v\nexpected:\n%x", version, err, encodeDBVersion(1))
+	}
+}
+
+// encodeVersion2Outputs encodes unspent outputs in the layout of version 2,
+// where the height is written without the coin base flag.
+func encodeVersion2Outputs(test *testing.T, outs tx_io.TXOutputs) []byte {
+	var buff bytes.Buffer
+	err := wire.WriteVarInt(&buff, uint64(outs.Height))
+	if err == nil {
+		err = wire.WriteVarInt(&buff, uint64(len(outs.Outputs)))
+	}
+	for idx := 0; err == nil && idx < len(outs.Outputs); idx++ {
+		err = wire.WriteVarInt(&buff, uint64(idx))
+		if err == nil {
+			err = outs.Outputs[idx].Encode(&buff)
+		}
+	}
+	if err != nil {
+		test.Fatal(err)
+	}
+	return buff.Bytes()
+}
+
+func TestMigrateDB_Version2(test *testing.T) {
+	defer params.SetActive(params.Active())
+	params.SetActive(&params.RegTestParams)
+	bc, cleanup := newTestGenesisChain(test)
+	defer cleanup()
+	block := mineTestBlock(test, bc, bc.tip, string(wallet.NewWallet().GetAddress()), 0)
+	if err := bc.AddBlock(block); err != nil {
+		test.Fatal(err)
+	}
+
+	// Blocks of version 2 are stored in the current format, unspent outputs
+	// are rewritten without coin base flags.
+	err := bc.db.Update(func(tx *db_pkg.Tx) error {
+		b := tx.Bucket(vars.UTXO_BUCKET)
+		state := make(map[string][]byte)
+		err := b.ForEach(func(k, v []byte) error {
+			state[string(k)] = encodeVersion2Outputs(test, tx_io.DeserializeOutputs(v))
+			return nil
+		})
+		for k, v := range state {
+			if err == nil {
+				err = b.Put([]byte(k), v)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(utils.BLOCKS_BUCKET).Put(utils.DB_VERSION, encodeDBVersion(2))
+	})
+	if err != nil {
+		test.Fatal(err)
+	}
+	path := bc.db.Path()
+	bc.CloseDB(false)
+
+	opened := NewBlockChain(config.Config{ChainPath: path})
+	defer opened.CloseDB(false)
+	if !bytes.Equal(opened.tip, block.Hash) {
+		test.Errorf("core.TestMigrateDB_Version2, tip:\nactual:\n%x\nexpected:\n%x", opened.tip, block.Hash)
+	}
+	coin, ok := UTXOSet{BlockChain: opened}.FindCoin(block.Transactions[0].Hash, 0)
+	if !ok || coin.Height != 1 || !coin.CoinBase {
+		test.Errorf("core.TestMigrateDB_Version2, coin base:\nactual:\n%+v, %v\nexpected:\nheight 1, coin base", coin, ok)
+	}
+	version, err := opened.db.Get(utils.DB_VERSION, utils.BLOCKS_BUCKET)
+	if err != nil || !bytes.Equal(version, encodeDBVersion(vars.DB_VERSION)) {
+		test.Errorf("core.TestMigrateDB_Version2: version:\nactual:\n%x, %v\nexpected:\n%x", version, err, encodeDBVersion(vars.DB_VERSION))
+	}
+}
@@ -16,71 +16,192 @@
 package core
 
 import (
-	"bytes"
+	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"log"
 	"math/big"
+	"runtime"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/YuriyLisovskiy/blockchain-go/src/consensus"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
-	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/x11"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
 	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
 )
 
+var ErrNonceSpaceExhausted = errors.New("nonce space exhausted")
+
+var (
+
+	// miningThreads is the number of goroutines searching for a nonce.
+	miningThreads = runtime.NumCPU()
+
+	// maxNonce is the highest nonce tried before the extra nonce is changed.
+	maxNonce uint32 = vars.MAX_NONCE
+)
+
 type Worker struct {
-	block  types.Block
+	header types.BlockHeader
 	target *big.Int
+	hasher consensus.PowHasher
 }
 
-func NewProofOfWork(block types.Block) Worker {
-	target := big.NewInt(1)
-	target.Lsh(target, uint(256-vars.TARGET_BITS))
-	worker := Worker{block, target}
+func NewProofOfWork(header types.BlockHeader) Worker {
+	target := consensus.CompactToBig(header.Bits)
+	worker := Worker{header, target, params.Active().PowHasher}
 	return worker
 }
 
-func (w *Worker) prepareData(nonce int) []byte {
-	data := bytes.Join(
-		[][]byte{
-			w.block.PrevBlockHash,
-			w.block.HashTransactions(),
-			utils.IntToHex(w.block.Timestamp),
-			utils.IntToHex(int64(vars.TARGET_BITS)),
-			utils.IntToHex(int64(nonce)),
-		},
-		[]byte{},
-	)
-	return data
+// SetMiningThreads changes the number of goroutines used for mining.
+func SetMiningThreads(threads int) {
+	miningThreads = threads
+}
+
+// Run searches for a nonce which makes the header's hash meet the target.
+// The nonce space is split between given number of goroutines, the hash
+// rate is logged periodically. ErrNonceSpaceExhausted is returned if none
+// of the nonces fits, and the context's error if it is cancelled.
+func (w *Worker) Run(ctx context.Context, threads int) (uint32, []byte, error) {
+	if threads < 1 {
+		threads = 1
+	}
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	found := make(chan powSolution, threads)
+	var hashes uint64
+	var wg sync.WaitGroup
+	space := uint64(maxNonce) + 1
+	for i := 0; i < threads; i++ {
+		start := space * uint64(i) / uint64(threads)
+		end := space * uint64(i+1) / uint64(threads)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if solution, ok := w.search(searchCtx, start, end, &hashes); ok {
+				found <- solution
+				cancel()
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	started := time.Now()
+	ticker := time.NewTicker(vars.HASHRATE_REPORT_INTERVAL * time.Second)
+	defer ticker.Stop()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		case <-ticker.C:
+			rate := float64(atomic.LoadUint64(&hashes)) / time.Since(started).Seconds()
+			utils.PrintLog(fmt.Sprintf("Mining with %d threads at %.2f kH/s\n", threads, rate/1000))
+		}
+	}
+	select {
+	case solution := <-found:
+		return solution.nonce, solution.hash, nil
+	default:
+	}
+	if ctx.Err() != nil {
+		return 0, []byte{}, ctx.Err()
+	}
+	return 0, []byte{}, ErrNonceSpaceExhausted
+}
+
+type powSolution struct {
+	nonce uint32
+	hash  []byte
 }
 
-func (w *Worker) Run() (int, []byte, error) {
+// search tries nonces in range [start, end) and adds the number of computed
+// hashes to the counter. The context is checked between batches of nonces.
+func (w *Worker) search(ctx context.Context, start, end uint64, hashes *uint64) (powSolution, bool) {
+	const batch = 1024
 	var hashInt big.Int
-	var hash [32]byte
-	nonce := 0
-	for nonce < vars.MAX_NONCE {
-		if atomic.LoadInt32(&vars.Syncing) == 1 {
-			return 0, []byte{}, errors.New("mining interrupt")
+
+	// Serialize the header once, only the nonce changes between attempts.
+	// The nonce is the last field of the header.
+	data := w.header.Serialize()
+	for nonce := start; nonce < end; nonce++ {
+		if (nonce-start)%batch == 0 && nonce != start {
+			atomic.AddUint64(hashes, batch)
+			if ctx.Err() != nil {
+				return powSolution{}, false
+			}
 		}
-		data := w.prepareData(nonce)
-		hash = x11.Sum256(data)
-		utils.PrintLog(fmt.Sprintf("Mining a new block: %x", hash))
-		hashInt.SetBytes(hash[:])
+		binary.LittleEndian.PutUint32(data[types.BLOCK_HEADER_SIZE-4:], uint32(nonce))
+		hash := w.hasher.Hash(data)
+		hashInt.SetBytes(hash)
 		if hashInt.Cmp(w.target) == -1 {
-			break
-		} else {
-			nonce++
+			return powSolution{uint32(nonce), hash}, true
+		}
+	}
+	return powSolution{}, false
+}
+
+// mineBlock finds the nonce and the hash of the block. When all nonces are
+// tried, the extra nonce of the coin base is incremented, which changes
+// the merkle root, and the search starts over.
+func mineBlock(ctx context.Context, block *types.Block, threads int) error {
+	extraNonce := uint32(0)
+	for {
+		worker := NewProofOfWork(block.BlockHeader)
+		nonce, hash, err := worker.Run(ctx, threads)
+		if err != ErrNonceSpaceExhausted {
+			if err == nil {
+				block.Nonce = nonce
+				block.Hash = hash
+			}
+			return err
+		}
+
+		// All extra nonces are tried when the counter wraps around.
+		extraNonce++
+		if extraNonce == 0 {
+			return err
 		}
+		setExtraNonce(&block.Transactions[0], block.Height, extraNonce)
+		block.MerkleRoot = block.HashTransactions()
 	}
-	fmt.Print("\n")
-	return nonce, hash[:], nil
 }
 
+// setExtraNonce writes the height and the extra nonce to the input script
+// of the coin base.
+func setExtraNonce(coinBase *types.Transaction, height int, extraNonce uint32) {
+	scriptSig, err := script.NewBuilder().AddInt64(int64(height)).AddInt64(int64(extraNonce)).Script()
+	if err != nil {
+		log.Panic(err)
+	}
+	coinBase.VIn[0].ScriptSig = scriptSig
+	coinBase.Hash = coinBase.CalcHash()
+}
+
+// Validate checks if the hash of the block's header meets the target.
 func (w *Worker) Validate() bool {
 	var hashInt big.Int
-	data := w.prepareData(w.block.Nonce)
-	hash := x11.Sum256(data)
-	hashInt.SetBytes(hash[:])
+	hash := w.CalcHash(w.header.Nonce)
+	hashInt.SetBytes(hash)
 	isValid := hashInt.Cmp(w.target) == -1
 	return isValid
 }
+
+// Work returns the expected number of hashes required to mine the block.
+func (w *Worker) Work() *big.Int {
+	return consensus.CalcWork(w.target)
+}
+
+// CalcHash recomputes the block's hash with given nonce.
+func (w *Worker) CalcHash(nonce uint32) []byte {
+	header := w.header
+	header.Nonce = nonce
+	return w.hasher.Hash(header.Serialize())
+}
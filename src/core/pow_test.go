@@ -15,8 +15,96 @@
 
 package core
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/consensus"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
 
 func TestPoW(test *testing.T) {
 
 }
+
+func TestWorker_Run(test *testing.T) {
+	header := newTestBlock(nil).BlockHeader
+	header.Bits = 0x2000ffff
+	worker := NewProofOfWork(header)
+	nonce, hash, err := worker.Run(context.Background(), 4)
+	if err != nil {
+		test.Fatal(err)
+	}
+	header.Nonce = nonce
+	worker = NewProofOfWork(header)
+	if !worker.Validate() || !bytes.Equal(hash, header.Hash()) {
+		test.Errorf("core.TestWorker_Run:\nactual:\n%x\nexpected:\n%x", hash, header.Hash())
+	}
+}
+
+func TestWorker_Run_Groestl(test *testing.T) {
+	defer params.SetActive(params.Active())
+	network := params.RegTestParams
+	network.PowHasher = consensus.GroestlHasher{}
+	params.SetActive(&network)
+
+	header := newTestBlock(nil).BlockHeader
+	header.Bits = 0x2000ffff
+	worker := NewProofOfWork(header)
+	nonce, hash, err := worker.Run(context.Background(), 2)
+	if err != nil {
+		test.Fatal(err)
+	}
+	header.Nonce = nonce
+	worker = NewProofOfWork(header)
+	expected := consensus.GroestlHash(header.Serialize())
+	if !worker.Validate() || !bytes.Equal(hash, expected) || !bytes.Equal(header.Hash(), expected) {
+		test.Errorf("core.TestWorker_Run_Groestl:\nactual:\n%x\nexpected:\n%x", hash, expected)
+	}
+}
+
+func TestWorker_Run_Stop(test *testing.T) {
+	defer func(max uint32) { maxNonce = max }(maxNonce)
+	maxNonce = 100
+
+	// The target of zero can not be met.
+	header := newTestBlock(nil).BlockHeader
+	header.Bits = 0
+	worker := NewProofOfWork(header)
+	if _, _, err := worker.Run(context.Background(), 3); err != ErrNonceSpaceExhausted {
+		test.Errorf("core.TestWorker_Run_Stop, exhausted:\nactual:\n%v\nexpected:\n%v", err, ErrNonceSpaceExhausted)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	maxNonce = vars.MAX_NONCE
+	if _, _, err := worker.Run(ctx, 3); err != context.Canceled {
+		test.Errorf("core.TestWorker_Run_Stop, cancelled:\nactual:\n%v\nexpected:\n%v", err, context.Canceled)
+	}
+}
+
+func TestMineBlock_ExtraNonce(test *testing.T) {
+	defer func(max uint32) { maxNonce = max }(maxNonce)
+
+	// A single nonce per merkle root makes the miner roll the extra nonce
+	// about 15 times of 16.
+	maxNonce = 0
+	address := string(wallet.NewWallet().GetAddress())
+	block := newTestBlock([]types.Transaction{NewCoinBaseTX(address, 0, 0)})
+	block.Bits = 0x1f0fffff
+	err := mineBlock(context.Background(), &block, 2)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if err := checkBlockSanity(block); err != nil {
+		test.Errorf("core.TestMineBlock_ExtraNonce:\nactual:\n%v\nexpected:\n%v", err, nil)
+	}
+	coinBase := NewCoinBaseTX(address, 0, 0)
+	setExtraNonce(&coinBase, 0, 1)
+	if coinBase.VIn[0].ScriptSig == nil || !coinBase.IsCoinBase() || !bytes.Equal(coinBase.Hash, coinBase.CalcHash()) {
+		test.Errorf("core.TestMineBlock_ExtraNonce, extra nonce:\nactual:\n%x\nexpected:\nnon-empty script", coinBase.VIn[0].ScriptSig)
+	}
+}
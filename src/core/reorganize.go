@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+// reorganize makes the branch ending with given block the main chain. Blocks
+// of the current main chain are disconnected down to the fork point, then
+// blocks of the new branch are validated and connected. If one of them is
+// invalid, the main chain is left untouched and the rest of the branch is
+// marked as failed.
+func (bc *BlockChain) reorganize(newTip []byte) error {
+	var detach, attach, failed [][]byte
+	err := bc.db.Update(func(tx *db_pkg.Tx) error {
+		var err error
+		blocks := tx.Bucket(utils.BLOCKS_BUCKET)
+		index := tx.Bucket(utils.BLOCK_INDEX_BUCKET)
+		detach, attach, err = findFork(index, blocks.Get(utils.LAST_BLOCK_HASH), newTip)
+		if err != nil {
+			return err
+		}
+		utxoSet := UTXOSet{BlockChain: *bc}
+		for _, hash := range detach {
+			err = utxoSet.disconnect(tx, DeserializeBlock(blocks.Get(hash)))
+			if err != nil {
+				return err
+			}
+		}
+		utxoBucket := tx.Bucket(vars.UTXO_BUCKET)
+		for i, hash := range attach {
+			entry, err := getBlockIndexEntry(index, hash)
+			if err != nil {
+				return err
+			}
+			if entry.Failed() {
+				failed = attach[i:]
+				return ErrBadPrevBlock
+			}
+			block := DeserializeBlock(blocks.Get(hash))
+			prevEntry, err := getBlockIndexEntry(index, block.PrevBlockHash)
+			if err != nil {
+				return err
+			}
+			ctx, err := newLockContext(prevEntry, func(blockHash []byte) (BlockIndexEntry, error) {
+				return getBlockIndexEntry(index, blockHash)
+			})
+			if err != nil {
+				return err
+			}
+			view := newUTXOView(func(txHash []byte, outIdx int) (Coin, bool) {
+				return findCoin(utxoBucket, txHash, outIdx)
+			}, block.Height)
+			err = checkBlockTransactions(block, view, ctx)
+			if err != nil {
+				failed = attach[i:]
+				return err
+			}
+			err = utxoSet.connect(tx, block)
+			if err != nil {
+				return err
+			}
+		}
+		return blocks.Put(utils.LAST_BLOCK_HASH, newTip)
+	})
+	for _, hash := range failed {
+		bc.markBlockFailed(hash)
+	}
+	if err != nil {
+		return err
+	}
+	utils.PrintLog(fmt.Sprintf("Reorganized chain: %d blocks disconnected, %d connected\n", len(detach), len(attach)))
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+// mineTestBlock mines a block on top of given block which pays the subsidy
+// and given fees to the address. Fees are not collected from transactions,
+// so a non-zero value makes the block invalid.
+func mineTestBlock(test *testing.T, bc BlockChain, prevHash []byte, address string, fees money.Amount) types.Block {
+	prevEntry, err := bc.GetBlockIndexEntry(prevHash)
+	if err != nil {
+		test.Fatal(err)
+	}
+	bits, err := bc.calcNextRequiredBits(prevEntry)
+	if err != nil {
+		test.Fatal(err)
+	}
+	height := prevEntry.Height + 1
+	block, err := NewBlock(
+		context.Background(),
+		[]types.Transaction{NewCoinBaseTX(address, height, fees)},
+		prevHash, height, bits, prevEntry.Header.Timestamp+1,
+	)
+	if err != nil {
+		test.Fatal(err)
+	}
+	return block
+}
+
+func TestBlockChain_AddBlock_Reorganize(test *testing.T) {
+	defer params.SetActive(params.Active())
+	params.SetActive(&params.RegTestParams)
+	bc, cleanup := newTestGenesisChain(test)
+	defer cleanup()
+	utxoSet := UTXOSet{BlockChain: bc}
+	genesis := bc.tip
+	tip := func() []byte {
+		hash, err := bc.db.Get(utils.LAST_BLOCK_HASH, utils.BLOCKS_BUCKET)
+		if err != nil {
+			test.Fatal(err)
+		}
+		return hash
+	}
+	unspent := func(block types.Block) bool {
+		_, ok := utxoSet.FindCoin(block.Transactions[0].Hash, 0)
+		return ok
+	}
+	addBlock := func(block types.Block) {
+		if err := bc.AddBlock(block); err != nil {
+			test.Fatal(err)
+		}
+	}
+
+	// The main chain is genesis <- a1, the fork genesis <- b1 <- b2 has
+	// more work once b2 is added.
+	a1 := mineTestBlock(test, bc, genesis, string(wallet.NewWallet().GetAddress()), 0)
+	addBlock(a1)
+	forkAddress := string(wallet.NewWallet().GetAddress())
+	b1 := mineTestBlock(test, bc, genesis, forkAddress, 0)
+	addBlock(b1)
+	if !bytes.Equal(tip(), a1.Hash) {
+		test.Errorf("core.TestBlockChain_AddBlock_Reorganize, fork of equal work:\nactual:\n%x\nexpected:\n%x", tip(), a1.Hash)
+	}
+	b2 := mineTestBlock(test, bc, b1.Hash, forkAddress, 0)
+	addBlock(b2)
+	if !bytes.Equal(tip(), b2.Hash) {
+		test.Errorf("core.TestBlockChain_AddBlock_Reorganize, heavier fork:\nactual:\n%x\nexpected:\n%x", tip(), b2.Hash)
+	}
+	if unspent(a1) || !unspent(b1) || !unspent(b2) {
+		test.Errorf("core.TestBlockChain_AddBlock_Reorganize, heavier fork utxo set:\nactual:\n%v %v %v\nexpected:\nfalse true true", unspent(a1), unspent(b1), unspent(b2))
+	}
+
+	// The fork b1 <- c2 <- c3 has more work, but c2 pays too much to its
+	// miner. Side chain blocks are accepted without checking transactions,
+	// so c2 is found invalid only when c3 triggers the reorganization.
+	c2 := mineTestBlock(test, bc, b1.Hash, forkAddress, 1)
+	addBlock(c2)
+	c3 := mineTestBlock(test, bc, c2.Hash, forkAddress, 0)
+	err := bc.AddBlock(c3)
+	if err != ErrBadCoinBaseAmount {
+		test.Errorf("core.TestBlockChain_AddBlock_Reorganize, invalid fork:\nactual:\n%v\nexpected:\n%v", err, ErrBadCoinBaseAmount)
+	}
+	if !bytes.Equal(tip(), b2.Hash) {
+		test.Errorf("core.TestBlockChain_AddBlock_Reorganize, invalid fork tip:\nactual:\n%x\nexpected:\n%x", tip(), b2.Hash)
+	}
+	if !unspent(b2) || unspent(c2) || unspent(c3) {
+		test.Errorf("core.TestBlockChain_AddBlock_Reorganize, invalid fork utxo set:\nactual:\n%v %v %v\nexpected:\ntrue false false", unspent(b2), unspent(c2), unspent(c3))
+	}
+	for _, block := range []types.Block{c2, c3} {
+		entry, err := bc.GetBlockIndexEntry(block.Hash)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !entry.Failed() {
+			test.Errorf("core.TestBlockChain_AddBlock_Reorganize, block %x is not marked failed", block.Hash)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import "encoding/binary"
+
+// Builder assembles scripts using the shortest encoding of each push.
+// The first error stops building and is returned by Script.
+type Builder struct {
+	script []byte
+	err    error
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// AddOp appends an opcode.
+func (b *Builder) AddOp(opcode byte) *Builder {
+	if b.err == nil {
+		b.script = append(b.script, opcode)
+	}
+	return b
+}
+
+// AddData appends a push of given data.
+func (b *Builder) AddData(data []byte) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(data) > MAX_SCRIPT_ELEMENT_SIZE {
+		b.err = ErrElementTooBig
+		return b
+	}
+	size := len(data)
+	switch {
+	case size == 0:
+		b.script = append(b.script, OP_0)
+		return b
+	case size == 1 && data[0] >= 1 && data[0] <= 16:
+		b.script = append(b.script, OP_1+data[0]-1)
+		return b
+	case size == 1 && data[0] == 0x81:
+		b.script = append(b.script, OP_1NEGATE)
+		return b
+	case size <= OP_DATA_75:
+		b.script = append(b.script, byte(size))
+	case size <= 0xff:
+		b.script = append(b.script, OP_PUSHDATA1, byte(size))
+	default:
+		var buf [2]byte
+		binary.LittleEndian.PutUint16(buf[:], uint16(size))
+		b.script = append(append(b.script, OP_PUSHDATA2), buf[:]...)
+	}
+	b.script = append(b.script, data...)
+	return b
+}
+
+// AddInt64 appends a push of a script number.
+func (b *Builder) AddInt64(n int64) *Builder {
+	if b.err != nil {
+		return b
+	}
+	switch {
+	case n == 0:
+		b.script = append(b.script, OP_0)
+	case n == -1:
+		b.script = append(b.script, OP_1NEGATE)
+	case n >= 1 && n <= 16:
+		b.script = append(b.script, byte(OP_1+n-1))
+	default:
+		return b.AddData(encodeNum(n))
+	}
+	return b
+}
+
+// Script returns the assembled script.
+func (b *Builder) Script() ([]byte, error) {
+	if b.err == nil && len(b.script) > MAX_SCRIPT_SIZE {
+		b.err = ErrScriptTooBig
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.script, nil
+}
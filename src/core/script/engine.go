@@ -0,0 +1,390 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/ripemd160"
+)
+
+// SigChecker verifies signatures and lock times against the transaction
+// which is being validated. subScript is the script being executed, it is
+// committed to by the signature hash.
+type SigChecker interface {
+	CheckSig(signature, pubKey, subScript []byte) bool
+
+	// CheckLockTime checks if the transaction's lock time is at least given one.
+	CheckLockTime(lockTime int64) bool
+
+	// CheckSequence checks if relative lock time of the input is at least given one.
+	CheckSequence(sequence int64) bool
+}
+
+// engine executes scripts over a shared stack.
+type engine struct {
+	stack     [][]byte
+	condStack []bool
+	checker   SigChecker
+	script    []byte
+	opCount   int
+}
+
+// Verify checks that scriptSig satisfies conditions of scriptPubKey.
+// scriptSig is executed first, then scriptPubKey is executed over the
+// resulting stack. If scriptPubKey is a pay-to-script-hash one, the last
+// item pushed by scriptSig is executed as a redeem script over the rest
+// of the stack as well.
+func Verify(scriptSig, scriptPubKey []byte, checker SigChecker) error {
+	if !IsPushOnly(scriptSig) {
+		return ErrSigPushOnly
+	}
+	e := engine{checker: checker}
+	err := e.execute(scriptSig)
+	if err != nil {
+		return err
+	}
+	sigStack := make([][]byte, len(e.stack))
+	copy(sigStack, e.stack)
+	err = e.execute(scriptPubKey)
+	if err != nil {
+		return err
+	}
+	if len(e.stack) == 0 || !asBool(e.stack[len(e.stack)-1]) {
+		return ErrEvalFalse
+	}
+	if !IsPayToScriptHash(scriptPubKey) {
+		return nil
+	}
+	if len(sigStack) == 0 {
+		return ErrEvalFalse
+	}
+	redeemScript := sigStack[len(sigStack)-1]
+	e.stack = sigStack[:len(sigStack)-1]
+	err = e.execute(redeemScript)
+	if err != nil {
+		return err
+	}
+	if len(e.stack) == 0 || !asBool(e.stack[len(e.stack)-1]) {
+		return ErrEvalFalse
+	}
+	return nil
+}
+
+func (e *engine) push(data []byte) {
+	e.stack = append(e.stack, data)
+}
+
+func (e *engine) pop() ([]byte, error) {
+	if len(e.stack) == 0 {
+		return nil, ErrStackUnderflow
+	}
+	data := e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+	return data, nil
+}
+
+func (e *engine) popNum() (int64, error) {
+	data, err := e.pop()
+	if err != nil {
+		return 0, err
+	}
+	return decodeNum(data, 4)
+}
+
+// peekNum decodes the top stack element as a number of at most maxLen bytes
+// without removing it.
+func (e *engine) peekNum(maxLen int) (int64, error) {
+	if len(e.stack) == 0 {
+		return 0, ErrStackUnderflow
+	}
+	return decodeNum(e.stack[len(e.stack)-1], maxLen)
+}
+
+func (e *engine) popBool() (bool, error) {
+	data, err := e.pop()
+	if err != nil {
+		return false, err
+	}
+	return asBool(data), nil
+}
+
+// executing checks if all enclosing conditional branches are taken.
+func (e *engine) executing() bool {
+	for _, cond := range e.condStack {
+		if !cond {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *engine) execute(script []byte) error {
+	if len(script) > MAX_SCRIPT_SIZE {
+		return ErrScriptTooBig
+	}
+	ops, err := parseScript(script)
+	if err != nil {
+		return err
+	}
+	e.script = script
+	e.condStack = nil
+	e.opCount = 0
+	for _, op := range ops {
+		if len(op.data) > MAX_SCRIPT_ELEMENT_SIZE {
+			return ErrElementTooBig
+		}
+		if op.opcode > OP_16 {
+			e.opCount++
+			if e.opCount > MAX_OPS_PER_SCRIPT {
+				return ErrTooManyOperations
+			}
+		}
+		if !e.executing() && (op.opcode < OP_IF || op.opcode > OP_ENDIF) {
+			continue
+		}
+		err = e.executeOp(op)
+		if err != nil {
+			return err
+		}
+		if len(e.stack) > MAX_STACK_SIZE {
+			return ErrStackOverflow
+		}
+	}
+	if len(e.condStack) != 0 {
+		return ErrUnbalancedConditional
+	}
+	return nil
+}
+
+func (e *engine) executeOp(op parsedOp) error {
+	if op.isPush() {
+		e.push(pushValue(op))
+		return nil
+	}
+	switch op.opcode {
+	case OP_NOP:
+	case OP_IF, OP_NOTIF:
+		cond := false
+		if e.executing() {
+			v, err := e.popBool()
+			if err != nil {
+				return err
+			}
+			cond = v == (op.opcode == OP_IF)
+		}
+		e.condStack = append(e.condStack, cond)
+	case OP_ELSE:
+		if len(e.condStack) == 0 {
+			return ErrUnbalancedConditional
+		}
+		e.condStack[len(e.condStack)-1] = !e.condStack[len(e.condStack)-1]
+	case OP_ENDIF:
+		if len(e.condStack) == 0 {
+			return ErrUnbalancedConditional
+		}
+		e.condStack = e.condStack[:len(e.condStack)-1]
+	case OP_VERIFY:
+		return e.verify()
+	case OP_RETURN:
+		return ErrEarlyReturn
+	case OP_2DROP:
+		if len(e.stack) < 2 {
+			return ErrStackUnderflow
+		}
+		e.stack = e.stack[:len(e.stack)-2]
+	case OP_DROP:
+		_, err := e.pop()
+		return err
+	case OP_DUP:
+		if len(e.stack) == 0 {
+			return ErrStackUnderflow
+		}
+		e.push(e.stack[len(e.stack)-1])
+	case OP_SWAP:
+		if len(e.stack) < 2 {
+			return ErrStackUnderflow
+		}
+		n := len(e.stack)
+		e.stack[n-1], e.stack[n-2] = e.stack[n-2], e.stack[n-1]
+	case OP_SIZE:
+		if len(e.stack) == 0 {
+			return ErrStackUnderflow
+		}
+		e.push(encodeNum(int64(len(e.stack[len(e.stack)-1]))))
+	case OP_EQUAL, OP_EQUALVERIFY:
+		a, err := e.pop()
+		if err != nil {
+			return err
+		}
+		b, err := e.pop()
+		if err != nil {
+			return err
+		}
+		e.push(fromBool(bytes.Equal(a, b)))
+		if op.opcode == OP_EQUALVERIFY {
+			return e.verify()
+		}
+	case OP_RIPEMD160, OP_SHA256, OP_HASH160, OP_HASH256:
+		data, err := e.pop()
+		if err != nil {
+			return err
+		}
+		e.push(hashOp(op.opcode, data))
+	case OP_CHECKSIG, OP_CHECKSIGVERIFY:
+		pubKey, err := e.pop()
+		if err != nil {
+			return err
+		}
+		signature, err := e.pop()
+		if err != nil {
+			return err
+		}
+		e.push(fromBool(e.checker.CheckSig(signature, pubKey, e.script)))
+		if op.opcode == OP_CHECKSIGVERIFY {
+			return e.verify()
+		}
+	case OP_CHECKMULTISIG, OP_CHECKMULTISIGVERIFY:
+		err := e.checkMultiSig()
+		if err != nil {
+			return err
+		}
+		if op.opcode == OP_CHECKMULTISIGVERIFY {
+			return e.verify()
+		}
+	case OP_CHECKLOCKTIMEVERIFY:
+
+		// Lock times may exceed the range of 4-byte numbers.
+		lockTime, err := e.peekNum(5)
+		if err != nil {
+			return err
+		}
+		if lockTime < 0 {
+			return ErrNegativeLockTime
+		}
+		if !e.checker.CheckLockTime(lockTime) {
+			return ErrUnsatisfiedLockTime
+		}
+	case OP_CHECKSEQUENCEVERIFY:
+		sequence, err := e.peekNum(5)
+		if err != nil {
+			return err
+		}
+		if sequence < 0 {
+			return ErrNegativeLockTime
+		}
+
+		// The operation behaves as OP_NOP if relative lock time is disabled.
+		if sequence&vars.SEQUENCE_LOCKTIME_DISABLE_FLAG != 0 {
+			break
+		}
+		if !e.checker.CheckSequence(sequence) {
+			return ErrUnsatisfiedLockTime
+		}
+	default:
+		return ErrInvalidOpcode
+	}
+	return nil
+}
+
+func (e *engine) verify() error {
+	v, err := e.popBool()
+	if err != nil {
+		return err
+	}
+	if !v {
+		return ErrVerifyFailed
+	}
+	return nil
+}
+
+// checkMultiSig executes OP_CHECKMULTISIG. The stack holds an empty dummy
+// element, m signatures, m, n public keys and n. Signatures must be given
+// in the same order as the keys they match.
+func (e *engine) checkMultiSig() error {
+	nKeys, err := e.popNum()
+	if err != nil {
+		return err
+	}
+	if nKeys < 0 || nKeys > MAX_PUBKEYS_PER_MULTISIG {
+		return ErrInvalidPubKeyCount
+	}
+	e.opCount += int(nKeys)
+	if e.opCount > MAX_OPS_PER_SCRIPT {
+		return ErrTooManyOperations
+	}
+	pubKeys := make([][]byte, nKeys)
+	for i := nKeys - 1; i >= 0; i-- {
+		pubKeys[i], err = e.pop()
+		if err != nil {
+			return err
+		}
+	}
+	nSigs, err := e.popNum()
+	if err != nil {
+		return err
+	}
+	if nSigs < 0 || nSigs > nKeys {
+		return ErrInvalidSignatureCount
+	}
+	signatures := make([][]byte, nSigs)
+	for i := nSigs - 1; i >= 0; i-- {
+		signatures[i], err = e.pop()
+		if err != nil {
+			return err
+		}
+	}
+	dummy, err := e.pop()
+	if err != nil {
+		return err
+	}
+	if len(dummy) != 0 {
+		return ErrNullDummy
+	}
+	keyIdx := 0
+	for sigIdx := 0; sigIdx < len(signatures); {
+		if len(signatures)-sigIdx > len(pubKeys)-keyIdx {
+			e.push(fromBool(false))
+			return nil
+		}
+		if e.checker.CheckSig(signatures[sigIdx], pubKeys[keyIdx], e.script) {
+			sigIdx++
+		}
+		keyIdx++
+	}
+	e.push(fromBool(true))
+	return nil
+}
+
+func hashOp(opcode byte, data []byte) []byte {
+	switch opcode {
+	case OP_RIPEMD160:
+		hasher := ripemd160.New()
+		hasher.Write(data)
+		return hasher.Sum(nil)
+	case OP_SHA256:
+		hash := sha256.Sum256(data)
+		return hash[:]
+	case OP_HASH160:
+		return Hash160(data)
+	default:
+		first := sha256.Sum256(data)
+		hash := sha256.Sum256(first[:])
+		return hash[:]
+	}
+}
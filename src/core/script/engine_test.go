@@ -0,0 +1,280 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+// fakeChecker accepts a signature if it equals "sig:" followed by the public key
+// and lock times which do not exceed the ones of the checker.
+type fakeChecker struct {
+	lockTime int64
+	sequence int64
+}
+
+func (fakeChecker) CheckSig(signature, pubKey, subScript []byte) bool {
+	return bytes.Equal(signature, fakeSig(pubKey))
+}
+
+func (c fakeChecker) CheckLockTime(lockTime int64) bool {
+	return lockTime <= c.lockTime
+}
+
+func (c fakeChecker) CheckSequence(sequence int64) bool {
+	return sequence <= c.sequence
+}
+
+func fakeSig(pubKey []byte) []byte {
+	return append([]byte("sig:"), pubKey...)
+}
+
+func fakePubKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 33)
+}
+
+func mustScript(script []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return script
+}
+
+var (
+	testPubKeys  = [][]byte{fakePubKey(1), fakePubKey(2), fakePubKey(3)}
+	testMultiSig = mustScript(MultiSigScript(testPubKeys, 2))
+	testSecret   = []byte("secret")
+	testHash     = sha256.Sum256(testSecret)
+
+	// Escrow: either both parties sign, or the arbiter signs together with one of them.
+	testEscrow = mustScript(NewBuilder().
+			AddOp(OP_IF).
+			AddInt64(2).AddData(testPubKeys[0]).AddData(testPubKeys[1]).AddInt64(2).AddOp(OP_CHECKMULTISIG).
+			AddOp(OP_ELSE).
+			AddData(testPubKeys[2]).AddOp(OP_CHECKSIGVERIFY).
+			AddInt64(1).AddData(testPubKeys[0]).AddData(testPubKeys[1]).AddInt64(2).AddOp(OP_CHECKMULTISIG).
+			AddOp(OP_ENDIF).Script())
+)
+
+var Verify_Data = []struct {
+	name         string
+	scriptSig    []byte
+	scriptPubKey []byte
+	expected     error
+}{
+	{
+		"p2pkh",
+		mustScript(PubKeyHashSigScript(fakeSig(testPubKeys[0]), testPubKeys[0])),
+		mustScript(PayToPubKeyHashScript(Hash160(testPubKeys[0]))),
+		nil,
+	},
+	{
+		"p2pkh, wrong key",
+		mustScript(PubKeyHashSigScript(fakeSig(testPubKeys[1]), testPubKeys[1])),
+		mustScript(PayToPubKeyHashScript(Hash160(testPubKeys[0]))),
+		ErrVerifyFailed,
+	},
+	{
+		"p2pkh, bad signature",
+		mustScript(PubKeyHashSigScript(fakeSig(testPubKeys[1]), testPubKeys[0])),
+		mustScript(PayToPubKeyHashScript(Hash160(testPubKeys[0]))),
+		ErrEvalFalse,
+	},
+	{
+		"multisig",
+		mustScript(MultiSigSigScript([][]byte{fakeSig(testPubKeys[0]), fakeSig(testPubKeys[2])})),
+		testMultiSig,
+		nil,
+	},
+	{
+		"multisig, not enough signatures",
+		mustScript(MultiSigSigScript([][]byte{fakeSig(testPubKeys[1])})),
+		testMultiSig,
+		ErrStackUnderflow,
+	},
+	{
+		"multisig, unordered signatures",
+		mustScript(MultiSigSigScript([][]byte{fakeSig(testPubKeys[2]), fakeSig(testPubKeys[0])})),
+		testMultiSig,
+		ErrEvalFalse,
+	},
+	{
+		"multisig, non-null dummy",
+		mustScript(NewBuilder().AddInt64(1).AddData(fakeSig(testPubKeys[0])).AddData(fakeSig(testPubKeys[1])).Script()),
+		testMultiSig,
+		ErrNullDummy,
+	},
+	{
+		"p2sh",
+		mustScript(ScriptHashSigScript(
+			mustScript(MultiSigSigScript([][]byte{fakeSig(testPubKeys[1]), fakeSig(testPubKeys[2])})),
+			testMultiSig,
+		)),
+		mustScript(PayToScriptHashScript(Hash160(testMultiSig))),
+		nil,
+	},
+	{
+		"p2sh, redeem script fails",
+		mustScript(ScriptHashSigScript(
+			mustScript(MultiSigSigScript([][]byte{fakeSig(testPubKeys[1]), fakeSig(testPubKeys[1])})),
+			testMultiSig,
+		)),
+		mustScript(PayToScriptHashScript(Hash160(testMultiSig))),
+		ErrEvalFalse,
+	},
+	{
+		"p2sh, wrong redeem script",
+		mustScript(ScriptHashSigScript([]byte{}, []byte{OP_1})),
+		mustScript(PayToScriptHashScript(Hash160(testMultiSig))),
+		ErrEvalFalse,
+	},
+	{
+		"hashlock",
+		mustScript(NewBuilder().AddData(testSecret).Script()),
+		mustScript(HashLockScript(testHash[:])),
+		nil,
+	},
+	{
+		"hashlock, wrong secret",
+		mustScript(NewBuilder().AddData([]byte("guess")).Script()),
+		mustScript(HashLockScript(testHash[:])),
+		ErrEvalFalse,
+	},
+	{
+		"null data",
+		[]byte{},
+		mustScript(NullDataScript([]byte("data"))),
+		ErrEarlyReturn,
+	},
+	{
+		"escrow, both parties",
+		mustScript(NewBuilder().AddOp(OP_0).AddData(fakeSig(testPubKeys[0])).AddData(fakeSig(testPubKeys[1])).AddInt64(1).Script()),
+		testEscrow,
+		nil,
+	},
+	{
+		"escrow, arbiter and one party",
+		mustScript(NewBuilder().AddOp(OP_0).AddData(fakeSig(testPubKeys[1])).AddData(fakeSig(testPubKeys[2])).AddOp(OP_0).Script()),
+		testEscrow,
+		nil,
+	},
+	{
+		"escrow, one party",
+		mustScript(NewBuilder().AddOp(OP_0).AddData(fakeSig(testPubKeys[1])).AddData(fakeSig(testPubKeys[1])).AddOp(OP_0).Script()),
+		testEscrow,
+		ErrVerifyFailed,
+	},
+	{
+		"signature script is not push only",
+		[]byte{OP_1, OP_DUP},
+		[]byte{OP_EQUAL},
+		ErrSigPushOnly,
+	},
+	{
+		"unbalanced conditional",
+		[]byte{OP_1},
+		[]byte{OP_IF, OP_1},
+		ErrUnbalancedConditional,
+	},
+	{
+		"stack underflow",
+		[]byte{},
+		[]byte{OP_DUP},
+		ErrStackUnderflow,
+	},
+	{
+		"invalid opcode",
+		[]byte{OP_1},
+		[]byte{0xff},
+		ErrInvalidOpcode,
+	},
+	{
+		"malformed push",
+		[]byte{OP_1},
+		[]byte{OP_DATA_1 + 1, 0x01},
+		ErrMalformedPush,
+	},
+	{
+		"empty stack",
+		[]byte{},
+		[]byte{},
+		ErrEvalFalse,
+	},
+}
+
+func TestVerify(test *testing.T) {
+	for _, data := range Verify_Data {
+		actual := Verify(data.scriptSig, data.scriptPubKey, fakeChecker{})
+		if actual != data.expected {
+			test.Errorf("script.TestVerify, %s:\nactual:\n%v\nexpected:\n%v", data.name, actual, data.expected)
+		}
+	}
+}
+
+func TestVerify_TooManyOperations(test *testing.T) {
+	scriptPubKey := bytes.Repeat([]byte{OP_NOP}, MAX_OPS_PER_SCRIPT+1)
+	scriptPubKey = append(scriptPubKey, OP_1)
+	if err := Verify([]byte{}, scriptPubKey, fakeChecker{}); err != ErrTooManyOperations {
+		test.Errorf("script.TestVerify_TooManyOperations:\nactual:\n%v\nexpected:\n%v", err, ErrTooManyOperations)
+	}
+}
+
+var LockTime_Data = []struct {
+	name         string
+	scriptSig    []byte
+	scriptPubKey []byte
+	expected     error
+}{
+	{"CLTV, reached", []byte{}, mustScript(NewBuilder().AddInt64(100).AddOp(OP_CHECKLOCKTIMEVERIFY).Script()), nil},
+	{"CLTV, not reached", []byte{}, mustScript(NewBuilder().AddInt64(101).AddOp(OP_CHECKLOCKTIMEVERIFY).Script()), ErrUnsatisfiedLockTime},
+	{"CLTV, negative", []byte{}, mustScript(NewBuilder().AddInt64(-1).AddOp(OP_CHECKLOCKTIMEVERIFY).Script()), ErrNegativeLockTime},
+	{"CLTV, 5-byte lock time", []byte{}, mustScript(NewBuilder().AddInt64(1 << 32).AddOp(OP_CHECKLOCKTIMEVERIFY).Script()), ErrUnsatisfiedLockTime},
+	{"CLTV, empty stack", []byte{}, []byte{OP_CHECKLOCKTIMEVERIFY}, ErrStackUnderflow},
+	{"CSV, reached", []byte{}, mustScript(NewBuilder().AddInt64(10).AddOp(OP_CHECKSEQUENCEVERIFY).Script()), nil},
+	{"CSV, not reached", []byte{}, mustScript(NewBuilder().AddInt64(11).AddOp(OP_CHECKSEQUENCEVERIFY).Script()), ErrUnsatisfiedLockTime},
+	{"CSV, disabled", []byte{}, mustScript(NewBuilder().AddInt64(1 << 31).AddOp(OP_CHECKSEQUENCEVERIFY).Script()), nil},
+
+	// The first key can spend after a delay, the second one at any time.
+	{
+		"CSV, delayed branch",
+		mustScript(NewBuilder().AddData(fakeSig(testPubKeys[0])).AddInt64(1).Script()),
+		testDelayed,
+		ErrUnsatisfiedLockTime,
+	},
+	{
+		"CSV, immediate branch",
+		mustScript(NewBuilder().AddData(fakeSig(testPubKeys[1])).AddInt64(0).Script()),
+		testDelayed,
+		nil,
+	},
+}
+
+var testDelayed = mustScript(NewBuilder().
+	AddOp(OP_IF).AddInt64(11).AddOp(OP_CHECKSEQUENCEVERIFY).AddOp(OP_DROP).AddData(testPubKeys[0]).
+	AddOp(OP_ELSE).AddData(testPubKeys[1]).
+	AddOp(OP_ENDIF).AddOp(OP_CHECKSIG).Script())
+
+func TestVerify_LockTime(test *testing.T) {
+	checker := fakeChecker{lockTime: 100, sequence: 10}
+	for _, data := range LockTime_Data {
+		actual := Verify(data.scriptSig, data.scriptPubKey, checker)
+		if actual != data.expected {
+			test.Errorf("script.TestVerify_LockTime, %s:\nactual:\n%v\nexpected:\n%v", data.name, actual, data.expected)
+		}
+	}
+}
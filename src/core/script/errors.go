@@ -0,0 +1,43 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import "errors"
+
+// Script errors.
+var (
+	ErrMalformedPush          = errors.New("malformed push")
+	ErrScriptTooBig           = errors.New("script is too big")
+	ErrElementTooBig          = errors.New("push exceeds maximum element size")
+	ErrTooManyOperations      = errors.New("too many operations")
+	ErrStackOverflow          = errors.New("stack size limit exceeded")
+	ErrStackUnderflow         = errors.New("operation on an empty stack")
+	ErrInvalidOpcode          = errors.New("invalid opcode")
+	ErrUnbalancedConditional  = errors.New("unbalanced conditional")
+	ErrEarlyReturn            = errors.New("OP_RETURN executed")
+	ErrVerifyFailed           = errors.New("verify operation failed")
+	ErrEvalFalse              = errors.New("script evaluated to false")
+	ErrSigPushOnly            = errors.New("signature script is not push only")
+	ErrNumberTooBig           = errors.New("number is too big")
+	ErrMinimalData            = errors.New("number is not minimally encoded")
+	ErrInvalidPubKeyCount     = errors.New("invalid public key count")
+	ErrInvalidSignatureCount  = errors.New("invalid signature count")
+	ErrNullDummy              = errors.New("multisig dummy argument is not empty")
+	ErrInvalidScriptParameter = errors.New("invalid script parameter")
+	ErrNotMultiSig            = errors.New("not a multisig script")
+	ErrNegativeLockTime       = errors.New("negative lock time")
+	ErrUnsatisfiedLockTime    = errors.New("lock time requirement is not satisfied")
+)
@@ -0,0 +1,230 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/ripemd160"
+)
+
+// parsedOp is a single operation of a script with data it pushes, if any.
+type parsedOp struct {
+	opcode byte
+	data   []byte
+}
+
+// parseScript splits the script into operations. On error, operations
+// parsed before the malformed push are returned.
+func parseScript(script []byte) ([]parsedOp, error) {
+	var ops []parsedOp
+	for i := 0; i < len(script); {
+		op := parsedOp{opcode: script[i]}
+		i++
+		var size int
+		switch {
+		case op.opcode >= OP_DATA_1 && op.opcode <= OP_DATA_75:
+			size = int(op.opcode)
+		case op.opcode == OP_PUSHDATA1:
+			if i+1 > len(script) {
+				return ops, ErrMalformedPush
+			}
+			size = int(script[i])
+			i++
+		case op.opcode == OP_PUSHDATA2:
+			if i+2 > len(script) {
+				return ops, ErrMalformedPush
+			}
+			size = int(binary.LittleEndian.Uint16(script[i:]))
+			i += 2
+		case op.opcode == OP_PUSHDATA4:
+			if i+4 > len(script) {
+				return ops, ErrMalformedPush
+			}
+			size = int(binary.LittleEndian.Uint32(script[i:]))
+			i += 4
+		}
+		if size < 0 || size > len(script)-i {
+			return ops, ErrMalformedPush
+		}
+		if size > 0 {
+			op.data = script[i : i+size]
+			i += size
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// isPush checks if the operation only pushes data or a small number.
+func (op parsedOp) isPush() bool {
+	return op.opcode <= OP_16 && op.opcode != 0x50
+}
+
+// IsPushOnly checks if the script consists of push operations only.
+func IsPushOnly(script []byte) bool {
+	ops, err := parseScript(script)
+	if err != nil {
+		return false
+	}
+	for _, op := range ops {
+		if !op.isPush() {
+			return false
+		}
+	}
+	return true
+}
+
+// PushedData returns data pushed by the script, which must be push only.
+func PushedData(script []byte) ([][]byte, error) {
+	ops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+	var data [][]byte
+	for _, op := range ops {
+		if !op.isPush() {
+			return nil, ErrSigPushOnly
+		}
+		data = append(data, pushValue(op))
+	}
+	return data, nil
+}
+
+// pushValue returns the value a push operation puts on the stack.
+func pushValue(op parsedOp) []byte {
+	switch {
+	case op.opcode == OP_1NEGATE:
+		return encodeNum(-1)
+	case op.opcode >= OP_1 && op.opcode <= OP_16:
+		return encodeNum(int64(op.opcode - OP_1 + 1))
+	case op.data == nil:
+		return []byte{}
+	}
+	return op.data
+}
+
+// Disasm returns a human readable representation of the script.
+func Disasm(script []byte) string {
+	ops, err := parseScript(script)
+	if err != nil {
+		return "[error]"
+	}
+	var result []string
+	for _, op := range ops {
+		switch {
+		case op.opcode == OP_0:
+			result = append(result, "0")
+		case op.opcode == OP_1NEGATE:
+			result = append(result, "-1")
+		case op.opcode >= OP_1 && op.opcode <= OP_16:
+			result = append(result, fmt.Sprintf("%d", op.opcode-OP_1+1))
+		case op.opcode <= OP_PUSHDATA4:
+			result = append(result, hex.EncodeToString(op.data))
+		default:
+			name, ok := opcodeNames[op.opcode]
+			if !ok {
+				name = fmt.Sprintf("OP_UNKNOWN_%#x", op.opcode)
+			}
+			result = append(result, name)
+		}
+	}
+	return strings.Join(result, " ")
+}
+
+// Hash160 computes RIPEMD160(SHA256(data)).
+func Hash160(data []byte) []byte {
+	hash := sha256.Sum256(data)
+	hasher := ripemd160.New()
+	hasher.Write(hash[:])
+	return hasher.Sum(nil)
+}
+
+// encodeNum encodes n as a script number: little-endian with the sign
+// in the highest bit of the last byte.
+func encodeNum(n int64) []byte {
+	if n == 0 {
+		return []byte{}
+	}
+	negative := n < 0
+	abs := uint64(n)
+	if negative {
+		abs = uint64(-n)
+	}
+	var result []byte
+	for abs > 0 {
+		result = append(result, byte(abs&0xff))
+		abs >>= 8
+	}
+	if result[len(result)-1]&0x80 != 0 {
+		extra := byte(0x00)
+		if negative {
+			extra = 0x80
+		}
+		result = append(result, extra)
+	} else if negative {
+		result[len(result)-1] |= 0x80
+	}
+	return result
+}
+
+// decodeNum decodes a minimally encoded script number of at most maxLen bytes.
+func decodeNum(data []byte, maxLen int) (int64, error) {
+	if len(data) > maxLen {
+		return 0, ErrNumberTooBig
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	// The last byte may only be zero, except for the sign bit, if the sign
+	// bit of the previous byte is set.
+	last := data[len(data)-1]
+	if last&0x7f == 0 && (len(data) == 1 || data[len(data)-2]&0x80 == 0) {
+		return 0, ErrMinimalData
+	}
+	var result int64
+	for i, b := range data {
+		result |= int64(b) << uint(8*i)
+	}
+	if last&0x80 != 0 {
+		result &= ^(int64(0x80) << uint(8*(len(data)-1)))
+		result = -result
+	}
+	return result, nil
+}
+
+// asBool interprets stack element as a boolean. Any non-zero value except
+// negative zero is true.
+func asBool(data []byte) bool {
+	for i, b := range data {
+		if b != 0 {
+			return !(i == len(data)-1 && b == 0x80)
+		}
+	}
+	return false
+}
+
+func fromBool(v bool) []byte {
+	if v {
+		return []byte{1}
+	}
+	return []byte{}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+// GetSigOpCount counts signature checks of the script. A multisig check
+// counts as MAX_PUBKEYS_PER_MULTISIG, unless accurate is set and the number
+// of public keys is pushed right before it, which is the case for redeem
+// scripts. Operations following a malformed push are not counted.
+func GetSigOpCount(script []byte, accurate bool) int {
+	ops, _ := parseScript(script)
+	count := 0
+	for i, op := range ops {
+		switch op.opcode {
+		case OP_CHECKSIG, OP_CHECKSIGVERIFY:
+			count++
+		case OP_CHECKMULTISIG, OP_CHECKMULTISIGVERIFY:
+			if accurate && i > 0 && ops[i-1].opcode >= OP_1 && ops[i-1].opcode <= OP_16 {
+				count += int(ops[i-1].opcode - OP_1 + 1)
+			} else {
+				count += MAX_PUBKEYS_PER_MULTISIG
+			}
+		}
+	}
+	return count
+}
+
+// GetScriptHashSigOpCount counts signature checks of the redeem script which
+// scriptSig provides to spend a pay-to-script-hash output. Returns 0 if
+// scriptPubKey is not a pay-to-script-hash one.
+func GetScriptHashSigOpCount(scriptSig, scriptPubKey []byte) int {
+	if !IsPayToScriptHash(scriptPubKey) {
+		return 0
+	}
+	data, err := PushedData(scriptSig)
+	if err != nil || len(data) == 0 {
+		return 0
+	}
+	return GetSigOpCount(data[len(data)-1], true)
+}
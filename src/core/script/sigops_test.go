@@ -0,0 +1,57 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import "testing"
+
+var GetSigOpCount_Data = []struct {
+	name     string
+	script   []byte
+	accurate bool
+	expected int
+}{
+	{"pay to pubkey hash", mustScript(PayToPubKeyHashScript(Hash160(testPubKeys[0]))), false, 1},
+	{"multisig", testMultiSig, false, MAX_PUBKEYS_PER_MULTISIG},
+	{"multisig, accurate", testMultiSig, true, 3},
+	{"escrow", testEscrow, false, 2*MAX_PUBKEYS_PER_MULTISIG + 1},
+	{"escrow, accurate", testEscrow, true, 5},
+	{"multisig without key count", []byte{OP_CHECKMULTISIG}, true, MAX_PUBKEYS_PER_MULTISIG},
+	{"malformed push", []byte{OP_CHECKSIG, OP_PUSHDATA1, 0x05, OP_CHECKSIG}, false, 1},
+	{"empty", []byte{}, false, 0},
+}
+
+func TestGetSigOpCount(test *testing.T) {
+	for _, data := range GetSigOpCount_Data {
+		actual := GetSigOpCount(data.script, data.accurate)
+		if actual != data.expected {
+			test.Errorf("script.TestGetSigOpCount, %s:\nactual:\n%d\nexpected:\n%d", data.name, actual, data.expected)
+		}
+	}
+}
+
+func TestGetScriptHashSigOpCount(test *testing.T) {
+	scriptSig := mustScript(ScriptHashSigScript(
+		mustScript(MultiSigSigScript([][]byte{fakeSig(testPubKeys[0]), fakeSig(testPubKeys[1])})),
+		testMultiSig,
+	))
+	scriptHash := mustScript(PayToScriptHashScript(Hash160(testMultiSig)))
+	if actual := GetScriptHashSigOpCount(scriptSig, scriptHash); actual != 3 {
+		test.Errorf("script.TestGetScriptHashSigOpCount:\nactual:\n%d\nexpected:\n%d", actual, 3)
+	}
+	if actual := GetScriptHashSigOpCount(scriptSig, testMultiSig); actual != 0 {
+		test.Errorf("script.TestGetScriptHashSigOpCount, bare multisig:\nactual:\n%d\nexpected:\n%d", actual, 0)
+	}
+}
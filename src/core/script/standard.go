@@ -0,0 +1,258 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import "crypto/sha256"
+
+// ScriptClass identifies a standard script template.
+type ScriptClass int
+
+const (
+	NON_STANDARD ScriptClass = iota
+	PUB_KEY_HASH
+	SCRIPT_HASH
+	MULTI_SIG
+	HASH_LOCK
+	NULL_DATA
+)
+
+// Template limits.
+const (
+	MAX_STANDARD_MULTISIG_KEYS = 3
+	MAX_NULL_DATA_SIZE         = 80
+)
+
+var scriptClassNames = map[ScriptClass]string{
+	NON_STANDARD: "nonstandard",
+	PUB_KEY_HASH: "pubkeyhash",
+	SCRIPT_HASH:  "scripthash",
+	MULTI_SIG:    "multisig",
+	HASH_LOCK:    "hashlock",
+	NULL_DATA:    "nulldata",
+}
+
+func (c ScriptClass) String() string {
+	return scriptClassNames[c]
+}
+
+// PayToPubKeyHashScript creates a script which is spent by a signature and
+// a public key with given hash:
+//
+//	OP_DUP OP_HASH160 <pubKeyHash> OP_EQUALVERIFY OP_CHECKSIG
+func PayToPubKeyHashScript(pubKeyHash []byte) ([]byte, error) {
+	if len(pubKeyHash) != 20 {
+		return nil, ErrInvalidScriptParameter
+	}
+	return NewBuilder().AddOp(OP_DUP).AddOp(OP_HASH160).AddData(pubKeyHash).
+		AddOp(OP_EQUALVERIFY).AddOp(OP_CHECKSIG).Script()
+}
+
+// PayToScriptHashScript creates a script which is spent by a redeem script
+// with given hash and data satisfying it:
+//
+//	OP_HASH160 <scriptHash> OP_EQUAL
+func PayToScriptHashScript(scriptHash []byte) ([]byte, error) {
+	if len(scriptHash) != 20 {
+		return nil, ErrInvalidScriptParameter
+	}
+	return NewBuilder().AddOp(OP_HASH160).AddData(scriptHash).AddOp(OP_EQUAL).Script()
+}
+
+// MultiSigScript creates a script which requires nRequired signatures
+// matching given public keys:
+//
+//	<nRequired> <pubKey>... <len(pubKeys)> OP_CHECKMULTISIG
+func MultiSigScript(pubKeys [][]byte, nRequired int) ([]byte, error) {
+	if nRequired < 1 || nRequired > len(pubKeys) || len(pubKeys) > MAX_PUBKEYS_PER_MULTISIG {
+		return nil, ErrInvalidScriptParameter
+	}
+	builder := NewBuilder().AddInt64(int64(nRequired))
+	for _, pubKey := range pubKeys {
+		builder.AddData(pubKey)
+	}
+	return builder.AddInt64(int64(len(pubKeys))).AddOp(OP_CHECKMULTISIG).Script()
+}
+
+// HashLockScript creates a script which is spent by a preimage of given
+// SHA-256 hash:
+//
+//	OP_SHA256 <hash> OP_EQUAL
+func HashLockScript(hash []byte) ([]byte, error) {
+	if len(hash) != sha256.Size {
+		return nil, ErrInvalidScriptParameter
+	}
+	return NewBuilder().AddOp(OP_SHA256).AddData(hash).AddOp(OP_EQUAL).Script()
+}
+
+// NullDataScript creates an unspendable script carrying given data:
+//
+//	OP_RETURN <data>
+func NullDataScript(data []byte) ([]byte, error) {
+	if len(data) > MAX_NULL_DATA_SIZE {
+		return nil, ErrInvalidScriptParameter
+	}
+	return NewBuilder().AddOp(OP_RETURN).AddData(data).Script()
+}
+
+// PubKeyHashSigScript creates a script spending a pay-to-pubkey-hash output.
+func PubKeyHashSigScript(signature, pubKey []byte) ([]byte, error) {
+	return NewBuilder().AddData(signature).AddData(pubKey).Script()
+}
+
+// MultiSigSigScript creates a script spending a multisig output.
+// Signatures must be in the order of public keys they match.
+func MultiSigSigScript(signatures [][]byte) ([]byte, error) {
+	builder := NewBuilder().AddOp(OP_0)
+	for _, signature := range signatures {
+		builder.AddData(signature)
+	}
+	return builder.Script()
+}
+
+// ScriptHashSigScript appends the redeem script to a script satisfying it,
+// making a script which spends a pay-to-script-hash output.
+func ScriptHashSigScript(redeemSigScript, redeemScript []byte) ([]byte, error) {
+	if !IsPushOnly(redeemSigScript) {
+		return nil, ErrSigPushOnly
+	}
+	push, err := NewBuilder().AddData(redeemScript).Script()
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, redeemSigScript...), push...), nil
+}
+
+// GetScriptClass recognises a standard script template.
+func GetScriptClass(script []byte) ScriptClass {
+	ops, err := parseScript(script)
+	if err != nil {
+		return NON_STANDARD
+	}
+	switch {
+	case isPubKeyHash(ops):
+		return PUB_KEY_HASH
+	case isScriptHash(ops):
+		return SCRIPT_HASH
+	case isMultiSig(ops):
+		return MULTI_SIG
+	case isHashLock(ops):
+		return HASH_LOCK
+	case isNullData(ops):
+		return NULL_DATA
+	}
+	return NON_STANDARD
+}
+
+// ExtractPubKeyHash returns the public key hash of a pay-to-pubkey-hash
+// script or nil for other scripts.
+func ExtractPubKeyHash(script []byte) []byte {
+	ops, err := parseScript(script)
+	if err != nil || !isPubKeyHash(ops) {
+		return nil
+	}
+	return ops[2].data
+}
+
+// ExtractScriptHash returns the script hash of a pay-to-script-hash script
+// or nil for other scripts.
+func ExtractScriptHash(script []byte) []byte {
+	ops, err := parseScript(script)
+	if err != nil || !isScriptHash(ops) {
+		return nil
+	}
+	return ops[1].data
+}
+
+// ExtractMultiSig returns public keys and the number of required signatures
+// of a standard multisig script.
+func ExtractMultiSig(script []byte) ([][]byte, int, error) {
+	ops, err := parseScript(script)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !isMultiSig(ops) {
+		return nil, 0, ErrNotMultiSig
+	}
+	var pubKeys [][]byte
+	for _, op := range ops[1 : len(ops)-2] {
+		pubKeys = append(pubKeys, op.data)
+	}
+	return pubKeys, int(ops[0].opcode-OP_1) + 1, nil
+}
+
+// IsPayToScriptHash checks if the script is exactly OP_HASH160 <20 bytes> OP_EQUAL.
+func IsPayToScriptHash(script []byte) bool {
+	return len(script) == 23 && script[0] == OP_HASH160 && script[1] == OP_DATA_1+19 && script[22] == OP_EQUAL
+}
+
+// IsUnspendable checks if outputs with the script can never be spent,
+// so they do not have to be kept in the UTXO set.
+func IsUnspendable(script []byte) bool {
+	return (len(script) > 0 && script[0] == OP_RETURN) || len(script) > MAX_SCRIPT_SIZE
+}
+
+func isPubKeyHash(ops []parsedOp) bool {
+	return len(ops) == 5 &&
+		ops[0].opcode == OP_DUP &&
+		ops[1].opcode == OP_HASH160 &&
+		ops[2].opcode == OP_DATA_1+19 &&
+		ops[3].opcode == OP_EQUALVERIFY &&
+		ops[4].opcode == OP_CHECKSIG
+}
+
+func isScriptHash(ops []parsedOp) bool {
+	return len(ops) == 3 &&
+		ops[0].opcode == OP_HASH160 &&
+		ops[1].opcode == OP_DATA_1+19 &&
+		ops[2].opcode == OP_EQUAL
+}
+
+func isMultiSig(ops []parsedOp) bool {
+	if len(ops) < 4 || ops[len(ops)-1].opcode != OP_CHECKMULTISIG {
+		return false
+	}
+	first, last := ops[0].opcode, ops[len(ops)-2].opcode
+	if first < OP_1 || first > OP_16 || last < OP_1 || last > OP_16 {
+		return false
+	}
+	nRequired, nKeys := int(first-OP_1)+1, int(last-OP_1)+1
+	if nKeys != len(ops)-3 || nRequired > nKeys || nKeys > MAX_STANDARD_MULTISIG_KEYS {
+		return false
+	}
+	for _, op := range ops[1 : len(ops)-2] {
+		if len(op.data) != 33 && len(op.data) != 65 {
+			return false
+		}
+	}
+	return true
+}
+
+func isHashLock(ops []parsedOp) bool {
+	return len(ops) == 3 &&
+		ops[0].opcode == OP_SHA256 &&
+		ops[1].opcode == OP_DATA_1+31 &&
+		ops[2].opcode == OP_EQUAL
+}
+
+func isNullData(ops []parsedOp) bool {
+	if len(ops) == 1 {
+		return ops[0].opcode == OP_RETURN
+	}
+	return len(ops) == 2 &&
+		ops[0].opcode == OP_RETURN &&
+		ops[1].isPush() &&
+		len(ops[1].data) <= MAX_NULL_DATA_SIZE
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package script
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+var GetScriptClass_Data = []struct {
+	script   []byte
+	expected ScriptClass
+}{
+	{mustScript(PayToPubKeyHashScript(Hash160(testPubKeys[0]))), PUB_KEY_HASH},
+	{mustScript(PayToScriptHashScript(Hash160(testMultiSig))), SCRIPT_HASH},
+	{testMultiSig, MULTI_SIG},
+	{mustScript(HashLockScript(testHash[:])), HASH_LOCK},
+	{mustScript(NullDataScript([]byte("data"))), NULL_DATA},
+	{[]byte{OP_RETURN}, NULL_DATA},
+	{testEscrow, NON_STANDARD},
+	{[]byte{OP_DATA_1 + 1, 0x01}, NON_STANDARD},
+	{[]byte{}, NON_STANDARD},
+}
+
+func TestGetScriptClass(test *testing.T) {
+	for i, data := range GetScriptClass_Data {
+		actual := GetScriptClass(data.script)
+		if actual != data.expected {
+			test.Errorf("script.TestGetScriptClass[%d]:\nactual:\n%v\nexpected:\n%v", i, actual, data.expected)
+		}
+	}
+}
+
+func TestExtract(test *testing.T) {
+	pubKeyHash := Hash160(testPubKeys[0])
+	actual := ExtractPubKeyHash(mustScript(PayToPubKeyHashScript(pubKeyHash)))
+	if !bytes.Equal(actual, pubKeyHash) {
+		test.Errorf("script.TestExtract:\nactual:\n%x\nexpected:\n%x", actual, pubKeyHash)
+	}
+	if actual := ExtractPubKeyHash(testMultiSig); actual != nil {
+		test.Errorf("script.TestExtract:\nactual:\n%x\nexpected:\nnil", actual)
+	}
+	scriptHash := Hash160(testMultiSig)
+	actual = ExtractScriptHash(mustScript(PayToScriptHashScript(scriptHash)))
+	if !bytes.Equal(actual, scriptHash) {
+		test.Errorf("script.TestExtract:\nactual:\n%x\nexpected:\n%x", actual, scriptHash)
+	}
+	pubKeys, nRequired, err := ExtractMultiSig(testMultiSig)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if nRequired != 2 || !reflect.DeepEqual(pubKeys, testPubKeys) {
+		test.Errorf("script.TestExtract:\nactual:\n%d %x\nexpected:\n%d %x", nRequired, pubKeys, 2, testPubKeys)
+	}
+	if _, _, err := ExtractMultiSig(testEscrow); err != ErrNotMultiSig {
+		test.Errorf("script.TestExtract:\nactual:\n%v\nexpected:\n%v", err, ErrNotMultiSig)
+	}
+}
+
+func TestStandardScripts_InvalidParameters(test *testing.T) {
+	errs := []error{}
+	_, err := PayToPubKeyHashScript([]byte("short"))
+	errs = append(errs, err)
+	_, err = PayToScriptHashScript([]byte("short"))
+	errs = append(errs, err)
+	_, err = MultiSigScript(testPubKeys, 4)
+	errs = append(errs, err)
+	_, err = MultiSigScript(testPubKeys, 0)
+	errs = append(errs, err)
+	_, err = HashLockScript([]byte("short"))
+	errs = append(errs, err)
+	_, err = NullDataScript(make([]byte, MAX_NULL_DATA_SIZE+1))
+	errs = append(errs, err)
+	for i, err := range errs {
+		if err != ErrInvalidScriptParameter {
+			test.Errorf("script.TestStandardScripts_InvalidParameters[%d]:\nactual:\n%v\nexpected:\n%v", i, err, ErrInvalidScriptParameter)
+		}
+	}
+}
+
+var Num_Data = []struct {
+	value   int64
+	encoded []byte
+}{
+	{0, []byte{}},
+	{1, []byte{0x01}},
+	{-1, []byte{0x81}},
+	{127, []byte{0x7f}},
+	{128, []byte{0x80, 0x00}},
+	{-128, []byte{0x80, 0x80}},
+	{255, []byte{0xff, 0x00}},
+	{256, []byte{0x00, 0x01}},
+	{-256, []byte{0x00, 0x81}},
+	{2147483647, []byte{0xff, 0xff, 0xff, 0x7f}},
+}
+
+func TestNum(test *testing.T) {
+	for i, data := range Num_Data {
+		encoded := encodeNum(data.value)
+		if !bytes.Equal(encoded, data.encoded) {
+			test.Errorf("script.TestNum[%d]:\nactual:\n%x\nexpected:\n%x", i, encoded, data.encoded)
+		}
+		decoded, err := decodeNum(data.encoded, 4)
+		if err != nil || decoded != data.value {
+			test.Errorf("script.TestNum[%d]:\nactual:\n%d %v\nexpected:\n%d", i, decoded, err, data.value)
+		}
+	}
+	for _, data := range [][]byte{{0x00}, {0x80}, {0x01, 0x00}, {0x7f, 0x80}} {
+		if _, err := decodeNum(data, 4); err != ErrMinimalData {
+			test.Errorf("script.TestNum:\nactual:\n%v\nexpected:\n%v", err, ErrMinimalData)
+		}
+	}
+	if _, err := decodeNum([]byte{1, 2, 3, 4, 5}, 4); err != ErrNumberTooBig {
+		test.Errorf("script.TestNum:\nactual:\n%v\nexpected:\n%v", err, ErrNumberTooBig)
+	}
+}
+
+func TestDisasm(test *testing.T) {
+	expected := "OP_DUP OP_HASH160 0101010101010101010101010101010101010101 OP_EQUALVERIFY OP_CHECKSIG"
+	actual := Disasm(mustScript(PayToPubKeyHashScript(bytes.Repeat([]byte{1}, 20))))
+	if actual != expected {
+		test.Errorf("script.TestDisasm:\nactual:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+// GetBlockSubsidy returns the amount of new coins which a block at given
+// height of the active network is allowed to create in addition to fees
+// of its transactions.
+func GetBlockSubsidy(height int) money.Amount {
+	return money.Amount(params.Active().Subsidy(height))
+}
+
+// GetTotalSubsidy returns the amount of coins issued by the blocks from
+// the genesis block up to given height inclusively.
+func GetTotalSubsidy(height int) money.Amount {
+	total := money.Amount(0)
+	for h := 0; h <= height; h++ {
+		total += GetBlockSubsidy(h)
+	}
+	return total
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+)
+
+// timeData collects offsets between clocks of peers and the local clock,
+// which are used to calculate network-adjusted time.
+type timeData struct {
+	mutex   sync.Mutex
+	offsets map[string]int64
+	offset  int64
+}
+
+var (
+	networkTime = newTimeData()
+
+	// maxFutureBlockTime is how far a block's timestamp may be ahead
+	// of network-adjusted time.
+	maxFutureBlockTime int64 = vars.MAX_FUTURE_BLOCK_TIME
+)
+
+func newTimeData() *timeData {
+	return &timeData{offsets: make(map[string]int64)}
+}
+
+// add records the offset of a peer and recalculates the median offset.
+// Each peer is counted once, the median is applied when there are at least
+// vars.MIN_TIME_SAMPLES peers and only if it does not exceed
+// vars.MAX_TIME_ADJUSTMENT.
+func (td *timeData) add(source string, offset int64) {
+	td.mutex.Lock()
+	defer td.mutex.Unlock()
+	if _, ok := td.offsets[source]; !ok && len(td.offsets) >= vars.MAX_TIME_SAMPLES {
+		return
+	}
+	td.offsets[source] = offset
+	if len(td.offsets) < vars.MIN_TIME_SAMPLES {
+		return
+	}
+	var offsets []int64
+	for _, o := range td.offsets {
+		offsets = append(offsets, o)
+	}
+	sort.Slice(offsets, func(i, j int) bool {
+		return offsets[i] < offsets[j]
+	})
+	median := offsets[len(offsets)/2]
+	if median > vars.MAX_TIME_ADJUSTMENT || median < -vars.MAX_TIME_ADJUSTMENT {
+		median = 0
+	}
+	td.offset = median
+}
+
+func (td *timeData) getOffset() int64 {
+	td.mutex.Lock()
+	defer td.mutex.Unlock()
+	return td.offset
+}
+
+// AddTimeData records the difference between the time reported by a peer
+// and the local time.
+func AddTimeData(source string, offset int64) {
+	networkTime.add(source, offset)
+}
+
+// GetTimeOffset returns the median offset of peers' clocks.
+func GetTimeOffset() int64 {
+	return networkTime.getOffset()
+}
+
+// GetAdjustedTime returns local time corrected by the median offset of peers' clocks.
+func GetAdjustedTime() int64 {
+	return time.Now().Unix() + GetTimeOffset()
+}
+
+// SetMaxFutureBlockTime changes how many seconds a block's timestamp may be
+// ahead of network-adjusted time.
+func SetMaxFutureBlockTime(seconds int64) {
+	maxFutureBlockTime = seconds
+}
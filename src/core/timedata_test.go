@@ -0,0 +1,55 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+)
+
+func TestTimeData(test *testing.T) {
+	data := []struct {
+		name     string
+		offsets  []int64
+		expected int64
+	}{
+		{"not enough peers", []int64{10, 10, 10, 10}, 0},
+		{"median", []int64{-20, 5, 10, 30, 600}, 10},
+		{"even number of peers", []int64{1, 2, 3, 4, 5, 6}, 4},
+		{"too large adjustment", []int64{1, vars.MAX_TIME_ADJUSTMENT + 1, vars.MAX_TIME_ADJUSTMENT + 1, vars.MAX_TIME_ADJUSTMENT + 1, 2}, 0},
+		{"too large negative adjustment", []int64{-vars.MAX_TIME_ADJUSTMENT - 1, -vars.MAX_TIME_ADJUSTMENT - 1, -vars.MAX_TIME_ADJUSTMENT - 1, 1, 2}, 0},
+	}
+	for _, d := range data {
+		td := newTimeData()
+		for i, offset := range d.offsets {
+			td.add(fmt.Sprintf("peer%d", i), offset)
+		}
+		if actual := td.getOffset(); actual != d.expected {
+			test.Errorf("core.TestTimeData, %s:\nactual:\n%d\nexpected:\n%d", d.name, actual, d.expected)
+		}
+	}
+
+	// A peer reporting its time several times is counted once.
+	td := newTimeData()
+	for i := 0; i < vars.MIN_TIME_SAMPLES; i++ {
+		td.add("peer", 100)
+	}
+	if actual := td.getOffset(); actual != 0 {
+		test.Errorf("core.TestTimeData, repeated peer:\nactual:\n%d\nexpected:\n%d", actual, 0)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"log"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+var (
+	ErrTxNotFound      = errors.New("transaction is not found")
+	ErrTxIndexDisabled = errors.New("transaction index is disabled")
+)
+
+// TxLocation points to a transaction of a main chain block: the hash of
+// the block and the position of the transaction within it.
+type TxLocation struct {
+	BlockHash []byte
+	Position  int
+}
+
+func (loc TxLocation) Serialize() []byte {
+	var buff bytes.Buffer
+	err := wire.WriteVarBytes(&buff, loc.BlockHash)
+	if err == nil {
+		err = wire.WriteVarInt(&buff, uint64(loc.Position))
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+	return buff.Bytes()
+}
+
+func DeserializeTxLocation(data []byte) TxLocation {
+	var loc TxLocation
+	r := bytes.NewReader(data)
+	var err error
+	loc.BlockHash, err = wire.ReadVarBytes(r)
+	if err == nil {
+		loc.Position, err = wire.ReadCount(r)
+	}
+	if err == nil && r.Len() != 0 {
+		err = wire.ErrTrailingData
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+	return loc
+}
+
+// indexTransactions adds locations of block's transactions to the
+// transaction index.
+func indexTransactions(b *db_pkg.Bucket, block types.Block) error {
+	for pos, tx := range block.Transactions {
+		loc := TxLocation{BlockHash: block.Hash, Position: pos}
+		err := b.Put(tx.Hash, loc.Serialize())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unindexTransactions removes block's transactions from the transaction
+// index. Entries which point to other blocks are kept.
+func unindexTransactions(b *db_pkg.Bucket, block types.Block) error {
+	for _, tx := range block.Transactions {
+		data := b.Get(tx.Hash)
+		if data == nil || !bytes.Equal(DeserializeTxLocation(data).BlockHash, block.Hash) {
+			continue
+		}
+		err := b.Delete(tx.Hash)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasTxIndex checks if the transaction index is maintained in the database.
+func (bc *BlockChain) HasTxIndex() bool {
+	found := false
+	err := bc.db.View(func(tx *db_pkg.Tx) error {
+		found = tx.Bucket(utils.TX_INDEX_BUCKET) != nil
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+	return found
+}
+
+// ReindexTransactions builds the transaction index from scratch by indexing
+// all blocks of the main chain. Once built, the index is kept up to date
+// when blocks are connected and disconnected.
+func (bc *BlockChain) ReindexTransactions() {
+	bc.rebuildIndex(utils.TX_INDEX_BUCKET, func(dbTx *db_pkg.Tx, b *db_pkg.Bucket, block types.Block) error {
+		return indexTransactions(b, block)
+	})
+}
+
+// DropTxIndex removes the transaction index from the database.
+func (bc *BlockChain) DropTxIndex() {
+	bc.dropIndex(utils.TX_INDEX_BUCKET)
+}
+
+// rebuildIndex recreates given index bucket and fills it by calling index
+// for each block of the main chain starting from the genesis block.
+func (bc *BlockChain) rebuildIndex(bucket []byte, index func(dbTx *db_pkg.Tx, b *db_pkg.Bucket, block types.Block) error) {
+	var hashes [][]byte
+	bci := bc.Iterator()
+	for !bci.End() {
+		hashes = append(hashes, bci.Next().Hash)
+	}
+	err := bc.db.Update(func(tx *db_pkg.Tx) error {
+		err := tx.DeleteBucket(bucket)
+		if err != nil && err != db_pkg.ErrBucketNotFound {
+			return err
+		}
+		b, err := tx.CreateBucket(bucket)
+		if err != nil {
+			return err
+		}
+		blocks := tx.Bucket(utils.BLOCKS_BUCKET)
+		for i := len(hashes) - 1; i >= 0; i-- {
+			err := index(tx, b, DeserializeBlock(blocks.Get(hashes[i])))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+func (bc *BlockChain) dropIndex(bucket []byte) {
+	err := bc.db.Update(func(tx *db_pkg.Tx) error {
+		err := tx.DeleteBucket(bucket)
+		if err == db_pkg.ErrBucketNotFound {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// GetTxLocation looks up the location of a main chain transaction in the
+// transaction index.
+func (bc *BlockChain) GetTxLocation(txHash []byte) (TxLocation, error) {
+	var loc TxLocation
+	err := bc.db.View(func(tx *db_pkg.Tx) error {
+		b := tx.Bucket(utils.TX_INDEX_BUCKET)
+		if b == nil {
+			return ErrTxIndexDisabled
+		}
+		data := b.Get(txHash)
+		if data == nil {
+			return ErrTxNotFound
+		}
+		loc = DeserializeTxLocation(data)
+		return nil
+	})
+	return loc, err
+}
+
+// GetRawTransaction retrieves a main chain transaction using the transaction
+// index. Returns the transaction and the hash of the block containing it.
+func (bc *BlockChain) GetRawTransaction(txHash []byte) (types.Transaction, []byte, error) {
+	loc, err := bc.GetTxLocation(txHash)
+	if err != nil {
+		return types.Transaction{}, nil, err
+	}
+	block, err := bc.GetBlock(loc.BlockHash)
+	if err != nil {
+		return types.Transaction{}, nil, err
+	}
+	if loc.Position >= len(block.Transactions) || !bytes.Equal(block.Transactions[loc.Position].Hash, txHash) {
+		return types.Transaction{}, nil, ErrTxNotFound
+	}
+	return block.Transactions[loc.Position], loc.BlockHash, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+func TestBlockChain_GetRawTransaction(test *testing.T) {
+	bc, cleanup := newTestBlockChain(test, vars.UTXO_BUCKET, utils.TX_INDEX_BUCKET)
+	defer cleanup()
+	utxoSet := UTXOSet{BlockChain: bc}
+
+	coinBase := []tx_io.TXInput{{PreviousTx: []byte{}, VOut: -1}}
+	block1 := newTestBlock([]types.Transaction{
+		newTestTx("cb1", coinBase, 50),
+	})
+	block2 := newTestBlock([]types.Transaction{
+		newTestTx("cb2", coinBase, 50),
+		newTestTx("tx1", []tx_io.TXInput{{PreviousTx: []byte("cb1"), VOut: 0}}, 20, 30),
+		newTestTx("tx2", []tx_io.TXInput{{PreviousTx: []byte("tx1"), VOut: 0}}, 20),
+	})
+	block2.Height = 1
+	for _, block := range []types.Block{block1, block2} {
+		err := bc.db.Put(block.Hash, block.Serialize(), utils.BLOCKS_BUCKET, false)
+		if err != nil {
+			test.Fatal(err)
+		}
+		utxoSet.Update(block)
+	}
+
+	data := []struct {
+		txHash    string
+		blockHash []byte
+		err       error
+	}{
+		{"cb1", block1.Hash, nil},
+		{"cb2", block2.Hash, nil},
+		{"tx1", block2.Hash, nil},
+		{"tx2", block2.Hash, nil},
+		{"unknown", nil, ErrTxNotFound},
+	}
+	for _, item := range data {
+		tx, blockHash, err := bc.GetRawTransaction([]byte(item.txHash))
+		if err != item.err {
+			test.Errorf("core.TestBlockChain_GetRawTransaction, %s:\nactual:\n%v\nexpected:\n%v", item.txHash, err, item.err)
+			continue
+		}
+		if !bytes.Equal(blockHash, item.blockHash) {
+			test.Errorf("core.TestBlockChain_GetRawTransaction, %s:\nactual:\n%x\nexpected:\n%x", item.txHash, blockHash, item.blockHash)
+		}
+		if err == nil && string(tx.Hash) != item.txHash {
+			test.Errorf("core.TestBlockChain_GetRawTransaction, %s:\nactual:\n%s\nexpected:\n%s", item.txHash, tx.Hash, item.txHash)
+		}
+	}
+
+	// Transactions of a disconnected block are removed from the index.
+	utxoSet.Disconnect(block2)
+	if _, _, err := bc.GetRawTransaction([]byte("tx1")); err != ErrTxNotFound {
+		test.Errorf("core.TestBlockChain_GetRawTransaction, disconnected:\nactual:\n%v\nexpected:\n%v", err, ErrTxNotFound)
+	}
+	if tx, err := bc.FindTransaction([]byte("cb1")); err != nil || string(tx.Hash) != "cb1" {
+		test.Errorf("core.TestBlockChain_GetRawTransaction, cb1 is not found: %v", err)
+	}
+
+	bc.DropTxIndex()
+	if _, _, err := bc.GetRawTransaction([]byte("cb1")); err != ErrTxIndexDisabled {
+		test.Errorf("core.TestBlockChain_GetRawTransaction, dropped:\nactual:\n%v\nexpected:\n%v", err, ErrTxIndexDisabled)
+	}
+}
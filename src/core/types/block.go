@@ -17,33 +17,100 @@ package types
 
 import (
 	"bytes"
-	"encoding/gob"
+	"io"
 	"log"
 
 	"github.com/YuriyLisovskiy/blockchain-go/src/consensus"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
 )
 
 type Block struct {
-	Timestamp     int64
-	Transactions  []Transaction
-	PrevBlockHash []byte
-	Hash          []byte
-	Nonce         int
-	Height        int
+	BlockHeader
+	Transactions []Transaction
+	Hash         []byte
+	Height       int
 }
 
 func (b Block) HashTransactions() []byte {
+	return b.MerkleTree().Root()
+}
+
+// MerkleTree builds the merkle tree of block's transactions.
+func (b Block) MerkleTree() *consensus.MerkleTree {
 	var transactions [][]byte
 	for _, tx := range b.Transactions {
 		transactions = append(transactions, tx.Serialize())
 	}
-	return consensus.ComputeMerkleRoot(transactions)
+	return consensus.NewMerkleTree(transactions)
+}
+
+// MerkleProof returns a proof of inclusion of the transaction with given
+// index, which can be verified against the block's header.
+func (b Block) MerkleProof(txIdx int) (consensus.MerkleProof, error) {
+	return b.MerkleTree().Proof(txIdx)
+}
+
+// Encode writes the block in the wire format: the header, the height and
+// transactions prefixed with their count. Block's hash is not written, it is
+// recomputed from the header by Decode.
+func (b Block) Encode(w io.Writer) error {
+	_, err := w.Write(b.BlockHeader.Serialize())
+	if err != nil {
+		return err
+	}
+	err = wire.WriteVarInt(w, uint64(b.Height))
+	if err != nil {
+		return err
+	}
+	err = wire.WriteVarInt(w, uint64(len(b.Transactions)))
+	if err != nil {
+		return err
+	}
+	for _, tx := range b.Transactions {
+		err = tx.Encode(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode reads a block written by Encode.
+func (b *Block) Decode(r io.Reader) error {
+	headerData := make([]byte, BLOCK_HEADER_SIZE)
+	_, err := io.ReadFull(r, headerData)
+	if err != nil {
+		return err
+	}
+	b.BlockHeader, err = DeserializeBlockHeader(headerData)
+	if err != nil {
+		return err
+	}
+	b.Hash = b.BlockHeader.Hash()
+	height, err := wire.ReadCount(r)
+	if err != nil {
+		return err
+	}
+	b.Height = height
+	count, err := wire.ReadCount(r)
+	if err != nil {
+		return err
+	}
+	b.Transactions = nil
+	for i := 0; i < count; i++ {
+		var tx Transaction
+		err = tx.Decode(r)
+		if err != nil {
+			return err
+		}
+		b.Transactions = append(b.Transactions, tx)
+	}
+	return nil
 }
 
 func (b Block) Serialize() []byte {
 	var result bytes.Buffer
-	encoder := gob.NewEncoder(&result)
-	err := encoder.Encode(b)
+	err := b.Encode(&result)
 	if err != nil {
 		log.Panic(err)
 	}
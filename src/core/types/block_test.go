@@ -15,8 +15,60 @@
 
 package types
 
-import "testing"
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+)
 
 func TestBlock(test *testing.T) {
 
 }
+
+func TestBlock_Serialize(test *testing.T) {
+	expected := Block{
+		BlockHeader:  BlockHeader_Data[1],
+		Transactions: []Transaction{newTestTransaction(), newTestTransaction()},
+		Height:       300,
+	}
+	expected.Hash = expected.BlockHeader.Hash()
+	var actual Block
+	err := actual.Decode(bytes.NewReader(expected.Serialize()))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		test.Errorf("types.TestBlock_Serialize:\nactual:\n%v\nexpected:\n%v", actual, expected)
+	}
+}
+
+func TestBlock_MerkleProof(test *testing.T) {
+	block := Block{BlockHeader: BlockHeader_Data[1]}
+	for i := 0; i < 5; i++ {
+		tx := newTestTransaction()
+		tx.Fee += money.Amount(i)
+		tx.Hash = tx.CalcHash()
+		block.Transactions = append(block.Transactions, tx)
+	}
+	block.MerkleRoot = block.HashTransactions()
+	for i, tx := range block.Transactions {
+		proof, err := block.MerkleProof(i)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if !block.BlockHeader.VerifyTxProof(tx, proof) {
+			test.Errorf("types.TestBlock_MerkleProof, %d: valid proof is rejected", i)
+		}
+	}
+
+	// The proof does not match a header of another block.
+	proof, err := block.MerkleProof(0)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if BlockHeader_Data[1].VerifyTxProof(block.Transactions[0], proof) {
+		test.Errorf("types.TestBlock_MerkleProof: proof is accepted by another header")
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/consensus"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+const (
+
+	// BLOCK_HEADER_SIZE is the size of serialized block header in bytes.
+	BLOCK_HEADER_SIZE = 80
+
+	// HASH_SIZE is the size of block and merkle root hashes in bytes.
+	HASH_SIZE = 32
+)
+
+// BlockHeader holds block's metadata. It commits to block's transactions
+// through the merkle root, so the block's hash is computed over the header only.
+type BlockHeader struct {
+	Version       int32
+	PrevBlockHash []byte
+	MerkleRoot    []byte
+	Timestamp     int64
+	Bits          uint32
+	Nonce         uint32
+}
+
+// Serialize encodes the header into BLOCK_HEADER_SIZE bytes. Integers are
+// written in little-endian order, timestamp is truncated to 32 bits and the
+// previous block hash of the genesis block is written as zeros.
+func (h BlockHeader) Serialize() []byte {
+	data := make([]byte, BLOCK_HEADER_SIZE)
+	binary.LittleEndian.PutUint32(data[0:4], uint32(h.Version))
+	copy(data[4:36], h.PrevBlockHash)
+	copy(data[36:68], h.MerkleRoot)
+	binary.LittleEndian.PutUint32(data[68:72], uint32(h.Timestamp))
+	binary.LittleEndian.PutUint32(data[72:76], h.Bits)
+	binary.LittleEndian.PutUint32(data[76:80], h.Nonce)
+	return data
+}
+
+// DeserializeBlockHeader decodes the header written by Serialize.
+func DeserializeBlockHeader(data []byte) (BlockHeader, error) {
+	if len(data) != BLOCK_HEADER_SIZE {
+		return BlockHeader{}, errors.New(fmt.Sprintf("invalid block header size %d", len(data)))
+	}
+	header := BlockHeader{
+		Version:       int32(binary.LittleEndian.Uint32(data[0:4])),
+		PrevBlockHash: append([]byte{}, data[4:36]...),
+		MerkleRoot:    append([]byte{}, data[36:68]...),
+		Timestamp:     int64(binary.LittleEndian.Uint32(data[68:72])),
+		Bits:          binary.LittleEndian.Uint32(data[72:76]),
+		Nonce:         binary.LittleEndian.Uint32(data[76:80]),
+	}
+	if isZeroHash(header.PrevBlockHash) {
+		header.PrevBlockHash = []byte{}
+	}
+	return header, nil
+}
+
+// Hash computes the header's hash, which is the block's hash, with
+// the proof of work function of the active network.
+func (h BlockHeader) Hash() []byte {
+	return params.Active().PowHasher.Hash(h.Serialize())
+}
+
+// VerifyTxProof checks if the proof connects given transaction to the
+// header's merkle root.
+func (h BlockHeader) VerifyTxProof(tx Transaction, proof consensus.MerkleProof) bool {
+	return proof.Verify(tx.Serialize(), h.MerkleRoot)
+}
+
+func isZeroHash(hash []byte) bool {
+	for _, b := range hash {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
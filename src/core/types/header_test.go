@@ -0,0 +1,76 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+var BlockHeader_Data = []BlockHeader{
+	{
+		Version:       1,
+		PrevBlockHash: []byte{},
+		MerkleRoot:    bytes.Repeat([]byte{0xab}, HASH_SIZE),
+		Timestamp:     1536000000,
+		Bits:          0x1f00ffff,
+		Nonce:         12345,
+	},
+	{
+		Version:       2,
+		PrevBlockHash: bytes.Repeat([]byte{0x01}, HASH_SIZE),
+		MerkleRoot:    bytes.Repeat([]byte{0x02}, HASH_SIZE),
+		Timestamp:     1395342829,
+		Bits:          0x1e0fffff,
+		Nonce:         0xffffffff,
+	},
+}
+
+func TestBlockHeader_Serialize(test *testing.T) {
+	for i, header := range BlockHeader_Data {
+		data := header.Serialize()
+		if len(data) != BLOCK_HEADER_SIZE {
+			test.Errorf("types.TestBlockHeader_Serialize[%d]:\nactual:\n%d\nexpected:\n%d", i, len(data), BLOCK_HEADER_SIZE)
+		}
+		actual, err := DeserializeBlockHeader(data)
+		if err != nil {
+			test.Errorf("types.TestBlockHeader_Serialize[%d]: %s", i, err)
+		}
+		if !reflect.DeepEqual(actual, header) {
+			test.Errorf("types.TestBlockHeader_Serialize[%d]:\nactual:\n%v\nexpected:\n%v", i, actual, header)
+		}
+	}
+	if _, err := DeserializeBlockHeader(make([]byte, BLOCK_HEADER_SIZE-1)); err == nil {
+		test.Errorf("types.TestBlockHeader_Serialize: short header is decoded without an error")
+	}
+}
+
+func TestBlockHeader_Hash(test *testing.T) {
+	header := BlockHeader_Data[0]
+	block := Block{BlockHeader: header}
+	expected := header.Hash()
+
+	// The hash does not depend on block's transactions.
+	block.Transactions = []Transaction{{}}
+	if actual := block.BlockHeader.Hash(); !bytes.Equal(actual, expected) {
+		test.Errorf("types.TestBlockHeader_Hash:\nactual:\n%x\nexpected:\n%x", actual, expected)
+	}
+	header.Nonce++
+	if actual := header.Hash(); bytes.Equal(actual, expected) {
+		test.Errorf("types.TestBlockHeader_Hash: hash does not change with the nonce")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import "github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+
+// IsFinal checks if the transaction can be included in a block with given
+// height and median time past of its previous block. Lock time below
+// vars.LOCKTIME_THRESHOLD is a block height, otherwise it is a timestamp.
+// Lock time is ignored if all inputs have final sequences.
+func (tx Transaction) IsFinal(height int, medianTimePast int64) bool {
+	if tx.LockTime == 0 {
+		return true
+	}
+	limit := int64(height)
+	if tx.LockTime >= vars.LOCKTIME_THRESHOLD {
+		limit = medianTimePast
+	}
+	if int64(tx.LockTime) < limit {
+		return true
+	}
+	for _, vin := range tx.VIn {
+		if vin.Sequence != vars.SEQUENCE_FINAL {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckLockTime implements OP_CHECKLOCKTIMEVERIFY: the transaction's lock time
+// must be of the same kind as given one and not less than it.
+func (c sigChecker) CheckLockTime(lockTime int64) bool {
+	txLockTime := int64(c.tx.LockTime)
+	if (txLockTime < vars.LOCKTIME_THRESHOLD) != (lockTime < vars.LOCKTIME_THRESHOLD) {
+		return false
+	}
+	if lockTime > txLockTime {
+		return false
+	}
+
+	// Lock time is not enforced for final inputs, so it could be bypassed.
+	return c.tx.VIn[c.inIdx].Sequence != vars.SEQUENCE_FINAL
+}
+
+// CheckSequence implements OP_CHECKSEQUENCEVERIFY: relative lock time of
+// the input must be of the same kind as given one and not less than it.
+func (c sigChecker) CheckSequence(sequence int64) bool {
+	if c.tx.Version < 2 {
+		return false
+	}
+	txSequence := int64(c.tx.VIn[c.inIdx].Sequence)
+	if txSequence&vars.SEQUENCE_LOCKTIME_DISABLE_FLAG != 0 {
+		return false
+	}
+	mask := int64(vars.SEQUENCE_LOCKTIME_TYPE_FLAG | vars.SEQUENCE_LOCKTIME_MASK)
+	txSequence &= mask
+	sequence &= mask
+	if (txSequence < vars.SEQUENCE_LOCKTIME_TYPE_FLAG) != (sequence < vars.SEQUENCE_LOCKTIME_TYPE_FLAG) {
+		return false
+	}
+	return sequence <= txSequence
+}
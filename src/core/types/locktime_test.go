@@ -0,0 +1,98 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+)
+
+func newTestLockedTx(lockTime uint32, sequences ...uint32) Transaction {
+	tx := Transaction{Version: 2, LockTime: lockTime}
+	for _, sequence := range sequences {
+		tx.VIn = append(tx.VIn, tx_io.TXInput{PreviousTx: []byte("prev"), Sequence: sequence})
+	}
+	return tx
+}
+
+var IsFinal_Data = []struct {
+	tx       Transaction
+	height   int
+	time     int64
+	expected bool
+}{
+	{newTestLockedTx(0, 0), 1, 1536000000, true},
+	{newTestLockedTx(100, 0), 101, 1536000000, true},
+	{newTestLockedTx(100, 0), 100, 1536000000, false},
+	{newTestLockedTx(100, vars.SEQUENCE_FINAL), 100, 1536000000, true},
+	{newTestLockedTx(100, vars.SEQUENCE_FINAL, 0), 100, 1536000000, false},
+	{newTestLockedTx(1536000000, 0), 1536000001, 1536000000, false},
+	{newTestLockedTx(1536000000, 0), 1, 1536000001, true},
+}
+
+func TestTransaction_IsFinal(test *testing.T) {
+	for i, data := range IsFinal_Data {
+		actual := data.tx.IsFinal(data.height, data.time)
+		if actual != data.expected {
+			test.Errorf("types.TestTransaction_IsFinal[%d]:\nactual:\n%t\nexpected:\n%t", i, actual, data.expected)
+		}
+	}
+}
+
+var CheckLockTime_Data = []struct {
+	tx       Transaction
+	lockTime int64
+	expected bool
+}{
+	{newTestLockedTx(100, 0), 100, true},
+	{newTestLockedTx(100, 0), 101, false},
+	{newTestLockedTx(100, vars.SEQUENCE_FINAL), 100, false},
+	{newTestLockedTx(1536000000, 0), 100, false},
+	{newTestLockedTx(1536000000, 0), 1500000000, true},
+}
+
+func TestSigChecker_CheckLockTime(test *testing.T) {
+	for i, data := range CheckLockTime_Data {
+		actual := sigChecker{tx: &data.tx}.CheckLockTime(data.lockTime)
+		if actual != data.expected {
+			test.Errorf("types.TestSigChecker_CheckLockTime[%d]:\nactual:\n%t\nexpected:\n%t", i, actual, data.expected)
+		}
+	}
+}
+
+var CheckSequence_Data = []struct {
+	tx       Transaction
+	sequence int64
+	expected bool
+}{
+	{newTestLockedTx(0, 10), 10, true},
+	{newTestLockedTx(0, 10), 11, false},
+	{newTestLockedTx(0, vars.SEQUENCE_LOCKTIME_TYPE_FLAG|10), 10, false},
+	{newTestLockedTx(0, vars.SEQUENCE_LOCKTIME_TYPE_FLAG|10), vars.SEQUENCE_LOCKTIME_TYPE_FLAG | 5, true},
+	{newTestLockedTx(0, vars.SEQUENCE_LOCKTIME_DISABLE_FLAG|10), 5, false},
+	{Transaction{Version: 1, VIn: []tx_io.TXInput{{Sequence: 10}}}, 5, false},
+}
+
+func TestSigChecker_CheckSequence(test *testing.T) {
+	for i, data := range CheckSequence_Data {
+		actual := sigChecker{tx: &data.tx}.CheckSequence(data.sequence)
+		if actual != data.expected {
+			test.Errorf("types.TestSigChecker_CheckSequence[%d]:\nactual:\n%t\nexpected:\n%t", i, actual, data.expected)
+		}
+	}
+}
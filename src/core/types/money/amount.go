@@ -0,0 +1,141 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+)
+
+var (
+	ErrOverflow      = errors.New("amount overflow")
+	ErrOutOfRange    = errors.New("amount is out of range")
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+// Amount is a value counted in the smallest currency units.
+type Amount int64
+
+// decimals is the number of digits after the decimal point which
+// can be represented by an Amount.
+var decimals = func() int {
+	n := 0
+	for c := int64(vars.COIN); c > 1; c /= 10 {
+		n++
+	}
+	return n
+}()
+
+// Add returns the sum of two amounts or ErrOverflow.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, ErrOverflow
+	}
+	return a + b, nil
+}
+
+// Sub returns the difference of two amounts or ErrOverflow.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+		return 0, ErrOverflow
+	}
+	return a - b, nil
+}
+
+// Mul returns the amount multiplied by n or ErrOverflow.
+func (a Amount) Mul(n int64) (Amount, error) {
+	if a == 0 || n == 0 {
+		return 0, nil
+	}
+	result := a * Amount(n)
+	if result/Amount(n) != a || (a == -1 && n == math.MinInt64) || (n == -1 && a == math.MinInt64) {
+		return 0, ErrOverflow
+	}
+	return result, nil
+}
+
+// Sum adds given amounts. It fails if the result or any of intermediate
+// sums is out of the money range.
+func Sum(amounts ...Amount) (Amount, error) {
+	total := Amount(0)
+	for _, a := range amounts {
+		if !InRange(a) {
+			return 0, ErrOutOfRange
+		}
+		var err error
+		total, err = total.Add(a)
+		if err != nil {
+			return 0, err
+		}
+		if !InRange(total) {
+			return 0, ErrOutOfRange
+		}
+	}
+	return total, nil
+}
+
+// InRange checks if the amount is not negative and does not exceed the
+// total money supply.
+func InRange(a Amount) bool {
+	return a >= 0 && a <= vars.MAX_MONEY
+}
+
+// String formats the amount as an exact decimal number of coins.
+func (a Amount) String() string {
+	sign := ""
+	abs := uint64(a)
+	if a < 0 {
+		sign = "-"
+		abs = uint64(-a)
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, abs/vars.COIN, decimals, abs%vars.COIN)
+}
+
+// ParseAmount parses a decimal number of coins, e.g. "12.5", without
+// rounding. Values with more fractional digits than can be represented
+// and values out of the money range are rejected.
+func ParseAmount(s string) (Amount, error) {
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" && frac == "" || len(frac) > decimals {
+		return 0, ErrInvalidAmount
+	}
+	frac += strings.Repeat("0", decimals-len(frac))
+	result := Amount(0)
+	for _, c := range whole + frac {
+		if c < '0' || c > '9' {
+			return 0, ErrInvalidAmount
+		}
+		var err error
+		result, err = result.Mul(10)
+		if err == nil {
+			result, err = result.Add(Amount(c - '0'))
+		}
+		if err != nil {
+			return 0, ErrOutOfRange
+		}
+	}
+	if !InRange(result) {
+		return 0, ErrOutOfRange
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package money
+
+import (
+	"math"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+)
+
+var ParseAmount_Data = []struct {
+	input    string
+	expected Amount
+	err      error
+}{
+	{input: "1", expected: vars.COIN},
+	{input: "0.000001", expected: 1},
+	{input: "12.5", expected: 12500000},
+	{input: ".25", expected: 250000},
+	{input: "3.", expected: 3 * vars.COIN},
+	{input: "21000000", expected: vars.MAX_MONEY},
+	{input: "0.1", expected: 100000},
+	{input: "21000000.000001", err: ErrOutOfRange},
+	{input: "99999999999999999999", err: ErrOutOfRange},
+	{input: "0.0000001", err: ErrInvalidAmount},
+	{input: "-1", err: ErrInvalidAmount},
+	{input: "1e6", err: ErrInvalidAmount},
+	{input: "1.2.3", err: ErrInvalidAmount},
+	{input: ".", err: ErrInvalidAmount},
+	{input: "", err: ErrInvalidAmount},
+}
+
+func TestParseAmount(test *testing.T) {
+	for i, data := range ParseAmount_Data {
+		actual, err := ParseAmount(data.input)
+		if err != data.err || actual != data.expected {
+			test.Errorf("money.TestParseAmount[%d]:\nactual:\n%d, %v\nexpected:\n%d, %v", i, actual, err, data.expected, data.err)
+		}
+	}
+}
+
+var String_Data = []struct {
+	input    Amount
+	expected string
+}{
+	{input: 0, expected: "0.000000"},
+	{input: 1, expected: "0.000001"},
+	{input: 12500000, expected: "12.500000"},
+	{input: vars.MAX_MONEY, expected: "21000000.000000"},
+	{input: -1500000, expected: "-1.500000"},
+}
+
+func TestAmount_String(test *testing.T) {
+	for i, data := range String_Data {
+		actual := data.input.String()
+		if actual != data.expected {
+			test.Errorf("money.TestAmount_String[%d]:\nactual:\n%s\nexpected:\n%s", i, actual, data.expected)
+		}
+		parsed, err := ParseAmount(actual)
+		if data.input >= 0 && (err != nil || parsed != data.input) {
+			test.Errorf("money.TestAmount_String[%d]: round trip gives %d, %v", i, parsed, err)
+		}
+	}
+}
+
+func TestAmount_Overflow(test *testing.T) {
+	if _, err := Amount(math.MaxInt64).Add(1); err != ErrOverflow {
+		test.Errorf("money.TestAmount_Overflow: Add:\nactual:\n%v\nexpected:\n%v", err, ErrOverflow)
+	}
+	if _, err := Amount(math.MinInt64).Sub(1); err != ErrOverflow {
+		test.Errorf("money.TestAmount_Overflow: Sub:\nactual:\n%v\nexpected:\n%v", err, ErrOverflow)
+	}
+	if _, err := Amount(math.MaxInt64 / 2).Mul(3); err != ErrOverflow {
+		test.Errorf("money.TestAmount_Overflow: Mul:\nactual:\n%v\nexpected:\n%v", err, ErrOverflow)
+	}
+	if actual, err := Amount(vars.COIN).Mul(3); err != nil || actual != 3*vars.COIN {
+		test.Errorf("money.TestAmount_Overflow: Mul:\nactual:\n%d, %v\nexpected:\n%d", actual, err, 3*vars.COIN)
+	}
+	if _, err := Sum(vars.MAX_MONEY, 1); err != ErrOutOfRange {
+		test.Errorf("money.TestAmount_Overflow: Sum:\nactual:\n%v\nexpected:\n%v", err, ErrOutOfRange)
+	}
+	if _, err := Sum(1, -1); err != ErrOutOfRange {
+		test.Errorf("money.TestAmount_Overflow: Sum of negative:\nactual:\n%v\nexpected:\n%v", err, ErrOutOfRange)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/secp256k1"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
+)
+
+// Signature hash types. The type is appended to each signature as its last
+// byte and defines which parts of the transaction the signature commits to.
+const (
+
+	// SIGHASH_ALL signs all inputs and outputs.
+	SIGHASH_ALL = 0x01
+
+	// SIGHASH_NONE signs all inputs and none of the outputs.
+	SIGHASH_NONE = 0x02
+
+	// SIGHASH_SINGLE signs all inputs and the output with the same index
+	// as the input being signed.
+	SIGHASH_SINGLE = 0x03
+
+	// SIGHASH_ANYONECANPAY is combined with one of the types above and
+	// restricts signed inputs to the one being signed.
+	SIGHASH_ANYONECANPAY = 0x80
+
+	sigHashMask = 0x1f
+)
+
+var (
+	ErrInputIndex         = errors.New("input index is out of range")
+	ErrSigHashSingle      = errors.New("SIGHASH_SINGLE input has no matching output")
+	ErrInvalidSigHashType = errors.New("invalid signature hash type")
+)
+
+// SignatureHash computes the message signed by the input with given index.
+// It is a double SHA-256 of the trimmed transaction followed by hash type,
+// where the signed input holds subScript, i.e. the script being executed,
+// and the rest of the inputs hold nothing.
+func (tx *Transaction) SignatureHash(inIdx int, subScript []byte, hashType uint8) ([]byte, error) {
+	if inIdx < 0 || inIdx >= len(tx.VIn) {
+		return nil, ErrInputIndex
+	}
+	baseType := hashType & sigHashMask
+	if baseType < SIGHASH_ALL || baseType > SIGHASH_SINGLE {
+		return nil, ErrInvalidSigHashType
+	}
+	txCopy := tx.TrimmedCopy()
+	txCopy.Hash = []byte{}
+	txCopy.VIn[inIdx].ScriptSig = subScript
+	if baseType != SIGHASH_ALL {
+
+		// Other inputs are allowed to update their sequences if not all outputs are signed.
+		for i := range txCopy.VIn {
+			if i != inIdx {
+				txCopy.VIn[i].Sequence = 0
+			}
+		}
+	}
+	switch baseType {
+	case SIGHASH_NONE:
+		txCopy.VOut = nil
+	case SIGHASH_SINGLE:
+		if inIdx >= len(txCopy.VOut) {
+			return nil, ErrSigHashSingle
+		}
+
+		// Outputs before the signed one are blanked, the ones after it are removed.
+		txCopy.VOut = txCopy.VOut[:inIdx+1]
+		for i := 0; i < inIdx; i++ {
+			txCopy.VOut[i] = tx_io.TXOutput{Value: money.Amount(-1), ScriptPubKey: []byte{}}
+		}
+	}
+	if hashType&SIGHASH_ANYONECANPAY != 0 {
+		txCopy.VIn = txCopy.VIn[inIdx : inIdx+1]
+	}
+	var buf bytes.Buffer
+	err := txCopy.Encode(&buf)
+	if err != nil {
+		return nil, err
+	}
+	err = wire.WriteUint32(&buf, uint32(hashType))
+	if err != nil {
+		return nil, err
+	}
+	first := sha256.Sum256(buf.Bytes())
+	hash := sha256.Sum256(first[:])
+	return hash[:], nil
+}
+
+// RawSignature creates a signature of the input with given index which can
+// be pushed to its script. It is [R || S] followed by the hash type byte.
+func (tx *Transaction) RawSignature(inIdx int, privateKey, subScript []byte, hashType uint8) ([]byte, error) {
+	hash, err := tx.SignatureHash(inIdx, subScript, hashType)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := secp256k1.Sign(hash, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the recovery id, only [R || S] is verified.
+	return append(signature[:64:64], hashType), nil
+}
+
+// SignInput signs the input with given index which spends a pay-to-pubkey-hash
+// output and sets its signature script.
+func (tx *Transaction) SignInput(inIdx int, privateKey, publicKey []byte, prevOut tx_io.TXOutput, hashType uint8) error {
+	signature, err := tx.RawSignature(inIdx, privateKey, prevOut.ScriptPubKey, hashType)
+	if err != nil {
+		return err
+	}
+	tx.VIn[inIdx].ScriptSig, err = script.PubKeyHashSigScript(signature, publicKey)
+	return err
+}
+
+// sigChecker verifies signatures of a transaction's input on behalf of
+// the script engine.
+type sigChecker struct {
+	tx    *Transaction
+	inIdx int
+}
+
+func (c sigChecker) CheckSig(signature, pubKey, subScript []byte) bool {
+	if len(signature) != 65 {
+		return false
+	}
+	hash, err := c.tx.SignatureHash(c.inIdx, subScript, signature[64])
+	if err != nil {
+		return false
+	}
+	return secp256k1.VerifySignature(pubKey, hash, signature[:64])
+}
+
+// verifyInput executes scripts of the input with given index and the output it spends.
+func (tx *Transaction) verifyInput(inIdx int, prevOut tx_io.TXOutput) bool {
+	checker := sigChecker{tx: tx, inIdx: inIdx}
+	return script.Verify(tx.VIn[inIdx].ScriptSig, prevOut.ScriptPubKey, checker) == nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+)
+
+// newTestSignedTx creates a transaction with two inputs and two outputs
+// and signs its inputs with given hash types.
+func newTestSignedTx(test *testing.T, w *wallet.Wallet, hashTypes ...uint8) (Transaction, []tx_io.TXOutput) {
+	scriptPubKey, err := script.PayToPubKeyHashScript(wallet.HashPubKey(w.PublicKey))
+	if err != nil {
+		test.Fatal(err)
+	}
+	prevOuts := []tx_io.TXOutput{
+		{Value: 10, ScriptPubKey: scriptPubKey},
+		{Value: 20, ScriptPubKey: scriptPubKey},
+	}
+	tx := Transaction{
+		Version: 1,
+		VIn: []tx_io.TXInput{
+			{PreviousTx: bytes.Repeat([]byte{0x01}, 32), VOut: 0},
+			{PreviousTx: bytes.Repeat([]byte{0x02}, 32), VOut: 1},
+		},
+		VOut: []tx_io.TXOutput{
+			{Value: 15, ScriptPubKey: []byte("first")},
+			{Value: 14, ScriptPubKey: []byte("second")},
+		},
+		Timestamp: 1536000000,
+		Fee:       1,
+	}
+	for i, hashType := range hashTypes {
+		err := tx.SignInput(i, w.PrivateKey, w.PublicKey, prevOuts[i], hashType)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+	tx.Hash = tx.CalcHash()
+	return tx, prevOuts
+}
+
+func TestTransaction_Verify(test *testing.T) {
+	w := wallet.NewWallet()
+	tx, prevOuts := newTestSignedTx(test, w, SIGHASH_ALL, SIGHASH_ALL)
+	if !tx.Verify(prevOuts) {
+		test.Errorf("types.TestTransaction_Verify: valid transaction is rejected")
+	}
+
+	// Forged hash.
+	forged := tx
+	forged.Hash = bytes.Repeat([]byte{0xff}, 32)
+	if forged.Verify(prevOuts) {
+		test.Errorf("types.TestTransaction_Verify: transaction with forged hash is accepted")
+	}
+
+	// Signature of one input can not be reused by another one.
+	swapped := tx
+	swapped.VIn = append([]tx_io.TXInput{}, tx.VIn...)
+	swapped.VIn[1].ScriptSig = tx.VIn[0].ScriptSig
+	swapped.Hash = swapped.CalcHash()
+	if swapped.Verify(prevOuts) {
+		test.Errorf("types.TestTransaction_Verify: signature of another input is accepted")
+	}
+}
+
+var SigHash_Data = []struct {
+	name     string
+	hashType uint8
+	modify   func(tx *Transaction)
+	valid    bool
+}{
+	{"ALL, spent outpoint changed", SIGHASH_ALL, func(tx *Transaction) { tx.VIn[0].VOut = 3 }, false},
+	{"ALL, output changed", SIGHASH_ALL, func(tx *Transaction) { tx.VOut[1].Value++ }, false},
+	{"ALL, input added", SIGHASH_ALL, func(tx *Transaction) { tx.VIn = append(tx.VIn, tx_io.TXInput{PreviousTx: []byte("new")}) }, false},
+	{"NONE, output changed", SIGHASH_NONE, func(tx *Transaction) { tx.VOut[1].Value++ }, true},
+	{"NONE, input added", SIGHASH_NONE, func(tx *Transaction) { tx.VIn = append(tx.VIn, tx_io.TXInput{PreviousTx: []byte("new")}) }, false},
+	{"SINGLE, matching output changed", SIGHASH_SINGLE, func(tx *Transaction) { tx.VOut[0].Value++ }, false},
+	{"SINGLE, other output changed", SIGHASH_SINGLE, func(tx *Transaction) { tx.VOut[1].Value++ }, true},
+	{"SINGLE, output added", SIGHASH_SINGLE, func(tx *Transaction) { tx.VOut = append(tx.VOut, tx_io.TXOutput{Value: 1}) }, true},
+	{"ALL|ANYONECANPAY, input added", SIGHASH_ALL | SIGHASH_ANYONECANPAY, func(tx *Transaction) { tx.VIn = append(tx.VIn, tx_io.TXInput{PreviousTx: []byte("new")}) }, true},
+	{"ALL|ANYONECANPAY, other input changed", SIGHASH_ALL | SIGHASH_ANYONECANPAY, func(tx *Transaction) { tx.VIn[1].VOut = 5 }, true},
+	{"ALL|ANYONECANPAY, output changed", SIGHASH_ALL | SIGHASH_ANYONECANPAY, func(tx *Transaction) { tx.VOut[0].Value++ }, false},
+}
+
+func TestTransaction_SignatureHash(test *testing.T) {
+	w := wallet.NewWallet()
+	for _, data := range SigHash_Data {
+		tx, prevOuts := newTestSignedTx(test, w, data.hashType)
+		tx.VIn = append([]tx_io.TXInput{}, tx.VIn...)
+		tx.VOut = append([]tx_io.TXOutput{}, tx.VOut...)
+		data.modify(&tx)
+		if actual := tx.verifyInput(0, prevOuts[0]); actual != data.valid {
+			test.Errorf("types.TestTransaction_SignatureHash, %s:\nactual:\n%t\nexpected:\n%t", data.name, actual, data.valid)
+		}
+	}
+
+	// SIGHASH_SINGLE requires an output with the same index.
+	tx, prevOuts := newTestSignedTx(test, w)
+	tx.VOut = tx.VOut[:1]
+	if _, err := tx.SignatureHash(1, prevOuts[1].ScriptPubKey, SIGHASH_SINGLE); err != ErrSigHashSingle {
+		test.Errorf("types.TestTransaction_SignatureHash:\nactual:\n%v\nexpected:\n%v", err, ErrSigHashSingle)
+	}
+	if _, err := tx.SignatureHash(0, prevOuts[0].ScriptPubKey, 0); err != ErrInvalidSigHashType {
+		test.Errorf("types.TestTransaction_SignatureHash:\nactual:\n%v\nexpected:\n%v", err, ErrInvalidSigHashType)
+	}
+}
+
+func TestTransaction_Verify_ScriptHash(test *testing.T) {
+	wallets := []*wallet.Wallet{wallet.NewWallet(), wallet.NewWallet(), wallet.NewWallet()}
+	var pubKeys [][]byte
+	for _, w := range wallets {
+		pubKeys = append(pubKeys, w.PublicKey)
+	}
+
+	// 2-of-3 escrow locked by the hash of the redeem script.
+	redeemScript, err := script.MultiSigScript(pubKeys, 2)
+	if err != nil {
+		test.Fatal(err)
+	}
+	scriptPubKey, err := script.PayToScriptHashScript(script.Hash160(redeemScript))
+	if err != nil {
+		test.Fatal(err)
+	}
+	prevOuts := []tx_io.TXOutput{{Value: 10, ScriptPubKey: scriptPubKey}}
+	tx := Transaction{
+		Version:   1,
+		VIn:       []tx_io.TXInput{{PreviousTx: bytes.Repeat([]byte{0x01}, 32), VOut: 0}},
+		VOut:      []tx_io.TXOutput{{Value: 9, ScriptPubKey: []byte("out")}},
+		Timestamp: 1536000000,
+		Fee:       1,
+	}
+	sign := func(signers ...*wallet.Wallet) {
+		var signatures [][]byte
+		for _, w := range signers {
+			signature, err := tx.RawSignature(0, w.PrivateKey, redeemScript, SIGHASH_ALL)
+			if err != nil {
+				test.Fatal(err)
+			}
+			signatures = append(signatures, signature)
+		}
+		sigScript, err := script.MultiSigSigScript(signatures)
+		if err != nil {
+			test.Fatal(err)
+		}
+		tx.VIn[0].ScriptSig, err = script.ScriptHashSigScript(sigScript, redeemScript)
+		if err != nil {
+			test.Fatal(err)
+		}
+		tx.Hash = tx.CalcHash()
+	}
+
+	sign(wallets[0], wallets[2])
+	if !tx.Verify(prevOuts) {
+		test.Errorf("types.TestTransaction_Verify_ScriptHash: valid transaction is rejected")
+	}
+	sign(wallets[1])
+	if tx.Verify(prevOuts) {
+		test.Errorf("types.TestTransaction_Verify_ScriptHash: transaction with a single signature is accepted")
+	}
+
+	// Signatures must follow the order of public keys.
+	sign(wallets[2], wallets[0])
+	if tx.Verify(prevOuts) {
+		test.Errorf("types.TestTransaction_Verify_ScriptHash: transaction with unordered signatures is accepted")
+	}
+}
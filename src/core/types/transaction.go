@@ -18,31 +18,129 @@ package types
 import (
 	"bytes"
 	"crypto/sha256"
-	"encoding/gob"
 	"encoding/hex"
+	"io"
 	"log"
 
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
-	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/secp256k1"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
 )
 
 type Transaction struct {
+	Version   int32
 	Hash      []byte
 	VIn       []tx_io.TXInput
 	VOut      []tx_io.TXOutput
+	LockTime  uint32
 	Timestamp int64
-	Fee       float64
+	Fee       money.Amount
 }
 
 func (tx Transaction) IsCoinBase() bool {
 	return len(tx.VIn) == 1 && len(tx.VIn[0].PreviousTx) == 0 && tx.VIn[0].VOut == -1
 }
 
+// Encode writes the transaction in the wire format: version, hash, inputs
+// and outputs prefixed with their counts, lock time, timestamp and fee.
+func (tx Transaction) Encode(w io.Writer) error {
+	err := wire.WriteUint32(w, uint32(tx.Version))
+	if err != nil {
+		return err
+	}
+	err = wire.WriteVarBytes(w, tx.Hash)
+	if err != nil {
+		return err
+	}
+	err = wire.WriteVarInt(w, uint64(len(tx.VIn)))
+	if err != nil {
+		return err
+	}
+	for _, vin := range tx.VIn {
+		err = vin.Encode(w)
+		if err != nil {
+			return err
+		}
+	}
+	err = wire.WriteVarInt(w, uint64(len(tx.VOut)))
+	if err != nil {
+		return err
+	}
+	for _, out := range tx.VOut {
+		err = out.Encode(w)
+		if err != nil {
+			return err
+		}
+	}
+	err = wire.WriteUint32(w, tx.LockTime)
+	if err != nil {
+		return err
+	}
+	err = wire.WriteUint64(w, uint64(tx.Timestamp))
+	if err != nil {
+		return err
+	}
+	return wire.WriteUint64(w, uint64(tx.Fee))
+}
+
+// Decode reads a transaction written by Encode.
+func (tx *Transaction) Decode(r io.Reader) error {
+	version, err := wire.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	tx.Version = int32(version)
+	tx.Hash, err = wire.ReadVarBytes(r)
+	if err != nil {
+		return err
+	}
+	count, err := wire.ReadCount(r)
+	if err != nil {
+		return err
+	}
+	tx.VIn = nil
+	for i := 0; i < count; i++ {
+		var vin tx_io.TXInput
+		err = vin.Decode(r)
+		if err != nil {
+			return err
+		}
+		tx.VIn = append(tx.VIn, vin)
+	}
+	count, err = wire.ReadCount(r)
+	if err != nil {
+		return err
+	}
+	tx.VOut = nil
+	for i := 0; i < count; i++ {
+		var out tx_io.TXOutput
+		err = out.Decode(r)
+		if err != nil {
+			return err
+		}
+		tx.VOut = append(tx.VOut, out)
+	}
+	tx.LockTime, err = wire.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	timestamp, err := wire.ReadUint64(r)
+	if err != nil {
+		return err
+	}
+	tx.Timestamp = int64(timestamp)
+	fee, err := wire.ReadUint64(r)
+	if err != nil {
+		return err
+	}
+	tx.Fee = money.Amount(fee)
+	return nil
+}
+
 func (tx Transaction) Serialize() []byte {
 	var encoded bytes.Buffer
-	enc := gob.NewEncoder(&encoded)
-	err := enc.Encode(tx)
+	err := tx.Encode(&encoded)
 	if err != nil {
 		log.Panic(err)
 	}
@@ -57,7 +155,7 @@ func (tx *Transaction) CalcHash() []byte {
 	return hash[:]
 }
 
-func (tx *Transaction) Sign(privateKey []byte, prevTXs map[string]Transaction) Transaction {
+func (tx *Transaction) Sign(privateKey, publicKey []byte, prevTXs map[string]Transaction) Transaction {
 	if tx.IsCoinBase() {
 		return *tx
 	}
@@ -67,22 +165,16 @@ func (tx *Transaction) Sign(privateKey []byte, prevTXs map[string]Transaction) T
 		}
 	}
 
-	//	fmt.Printf("\n\nPUB KEY (sign): %x\n", append(privateKey.PublicKey.X.Bytes(), privateKey.PublicKey.Y.Bytes()...))
-
-	txCopy := tx.TrimmedCopy()
-
 	for inID, vIn := range tx.VIn {
 		prevTx := prevTXs[hex.EncodeToString(vIn.PreviousTx)]
-		txCopy.VIn[inID].Signature = nil
-		txCopy.VIn[inID].PubKey = prevTx.VOut[vIn.VOut].PubKeyHash
-		//	dataToSign := fmt.Sprintf("%x\n", txCopy)
-		signature, err := secp256k1.Sign(tx.Hash, privateKey)
+		err := tx.SignInput(inID, privateKey, publicKey, prevTx.VOut[vIn.VOut], SIGHASH_ALL)
 		if err != nil {
 			log.Panic(err)
 		}
-		tx.VIn[inID].Signature = signature
-		//	txCopy.VIn[inID].PubKey = nil
 	}
+
+	// Transaction's hash commits to signatures, so it is computed last.
+	tx.Hash = tx.CalcHash()
 	return *tx
 }
 
@@ -90,16 +182,21 @@ func (tx *Transaction) TrimmedCopy() Transaction {
 	var inputs []tx_io.TXInput
 	var outputs []tx_io.TXOutput
 	for _, vin := range tx.VIn {
-		inputs = append(inputs, tx_io.TXInput{PreviousTx: vin.PreviousTx, VOut: vin.VOut, PubKey: nil, Signature: nil})
+		inputs = append(inputs, tx_io.TXInput{PreviousTx: vin.PreviousTx, VOut: vin.VOut, ScriptSig: nil, Sequence: vin.Sequence})
 	}
 	for _, vOut := range tx.VOut {
-		outputs = append(outputs, tx_io.TXOutput{Value: vOut.Value, PubKeyHash: vOut.PubKeyHash})
+		outputs = append(outputs, tx_io.TXOutput{Value: vOut.Value, ScriptPubKey: vOut.ScriptPubKey})
 	}
-	txCopy := Transaction{Hash: tx.Hash, VIn: inputs, VOut: outputs, Timestamp: tx.Timestamp, Fee: tx.Fee}
+	txCopy := Transaction{Version: tx.Version, Hash: tx.Hash, VIn: inputs, VOut: outputs, LockTime: tx.LockTime, Timestamp: tx.Timestamp, Fee: tx.Fee}
 	return txCopy
 }
 
-func (tx *Transaction) Verify(prevTXs map[string]Transaction) bool {
+// Verify checks transaction's hash and signatures of its inputs. prevOuts
+// holds the outputs spent by each input, in the order of inputs.
+func (tx *Transaction) Verify(prevOuts []tx_io.TXOutput) bool {
+	if !bytes.Equal(tx.Hash, tx.CalcHash()) {
+		return false
+	}
 	if tx.IsCoinBase() {
 		return true
 	}
@@ -109,51 +206,23 @@ func (tx *Transaction) Verify(prevTXs map[string]Transaction) bool {
 	if len(tx.VOut) == 0 {
 		log.Panic("ERROR: bad-txns-vout-empty")
 	}
-	for _, vin := range tx.VIn {
-		if prevTXs[hex.EncodeToString(vin.PreviousTx)].Hash == nil {
-			log.Panic("ERROR: Previous transaction is not correct")
-		}
+	if len(prevOuts) != len(tx.VIn) {
+		log.Panic("ERROR: Previous outputs are not correct")
 	}
-	txCopy := tx.TrimmedCopy()
-
-	//	fmt.Printf("LOG: tx hash: %x == tx copy hash: %x ? %t",
-	//		tx.CalcHash(), txCopy.CalcHash(),
-	//		fmt.Sprintf("%x", tx.CalcHash()) == fmt.Sprintf("%x", txCopy.CalcHash()),
-	//	)
-	for inID, vin := range tx.VIn {
-		prevTx := prevTXs[hex.EncodeToString(vin.PreviousTx)]
-		//	txCopy.VIn[inID].Signature = nil
-		txCopy.VIn[inID].PubKey = prevTx.VOut[vin.VOut].PubKeyHash
-		/*
-		r := big.Int{}
-		s := big.Int{}
-		sigLen := len(vin.Signature)
-		r.SetBytes(vin.Signature[:(sigLen / 2)])
-		s.SetBytes(vin.Signature[(sigLen / 2):])
-		*/
-		//	x, y := big.Int{}, big.Int{}
-		//	x.SetBytes(vin.PubKey[:(len(vin.PubKey) / 2)])
-		//	y.SetBytes(vin.PubKey[(len(vin.PubKey) / 2):])
-
-		//	pubKey := ecdsa.PublicKey{X: &x, Y: &y, Curve: secp256k1.S256()}
-
-		//	fmt.Printf("\nVIN PUB KEY (verify): %x\n\n", vin.PubKey)
-		//	fmt.Printf("\nPUB KEY (verify): %x\n\n\n", pubKey)
-
-		if !secp256k1.VerifySignature(vin.PubKey, tx.Hash, vin.Signature) {
+	for inID := range tx.VIn {
+		if !tx.verifyInput(inID, prevOuts[inID]) {
 			return false
 		}
-		//	txCopy.VIn[inID].PubKey = nil
 	}
 	return true
 }
 
-func (tx *Transaction) CalculateFee(feePerByte float64) float64 {
+func (tx *Transaction) CalculateFee(feePerByte money.Amount) (money.Amount, error) {
 	if tx.IsCoinBase() {
-		return 0.0
+		return 0, nil
 	}
 	if feePerByte < vars.MIN_FEE_PER_BYTE {
 		feePerByte = vars.MIN_FEE_PER_BYTE
 	}
-	return float64(len(tx.VIn)*148+len(tx.VOut)*34+10) * vars.MIN_FEE_PER_BYTE
+	return feePerByte.Mul(int64(len(tx.VIn)*148 + len(tx.VOut)*34 + 10))
 }
@@ -15,8 +15,55 @@
 
 package types
 
-import "testing"
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+)
 
 func TestTransaction(test *testing.T) {
 
 }
+
+func newTestTransaction() Transaction {
+	return Transaction{
+		Version: 1,
+		Hash:    bytes.Repeat([]byte{0x11}, 32),
+		VIn: []tx_io.TXInput{
+			{PreviousTx: bytes.Repeat([]byte{0x22}, 32), VOut: 1, ScriptSig: []byte("scriptsig"), Sequence: 10},
+			{PreviousTx: []byte{}, VOut: -1, ScriptSig: []byte{}, Sequence: 0xffffffff},
+		},
+		VOut: []tx_io.TXOutput{
+			{Value: 5000000, ScriptPubKey: []byte("scriptpubkey")},
+		},
+		LockTime:  600000,
+		Timestamp: 1536000000,
+		Fee:       3000,
+	}
+}
+
+func TestTransaction_Serialize(test *testing.T) {
+	expected := newTestTransaction()
+	data := expected.Serialize()
+	var actual Transaction
+	err := actual.Decode(bytes.NewReader(data))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		test.Errorf("types.TestTransaction_Serialize:\nactual:\n%v\nexpected:\n%v", actual, expected)
+	}
+
+	// Serialization is deterministic, so equal transactions have equal hashes.
+	if !bytes.Equal(actual.CalcHash(), expected.CalcHash()) {
+		test.Errorf("types.TestTransaction_Serialize: hashes of equal transactions differ")
+	}
+
+	// Truncated data must be rejected.
+	err = actual.Decode(bytes.NewReader(data[:len(data)-1]))
+	if err == nil {
+		test.Errorf("types.TestTransaction_Serialize: truncated transaction is decoded without an error")
+	}
+}
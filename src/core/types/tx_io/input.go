@@ -16,19 +16,55 @@
 package tx_io
 
 import (
-	"bytes"
+	"io"
 
-	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
 )
 
+// TXInput spends an output of a previous transaction. ScriptSig provides
+// data, e.g. signatures, which satisfies the output's ScriptPubKey.
+// Sequence holds relative lock time of the input, see vars.SEQUENCE_*.
 type TXInput struct {
 	PreviousTx []byte
 	VOut       int
-	Signature  []byte
-	PubKey    []byte
+	ScriptSig  []byte
+	Sequence   uint32
 }
 
-func (in TXInput) UsesKey(pubKeyHash []byte) bool {
-	return bytes.Compare(wallet.HashPubKey(in.PubKey), pubKeyHash) == 0
+// Encode writes the input in the wire format. The output index is written
+// as a 32-bit value, so -1 of a coin base input becomes 0xffffffff.
+func (in TXInput) Encode(w io.Writer) error {
+	err := wire.WriteVarBytes(w, in.PreviousTx)
+	if err != nil {
+		return err
+	}
+	err = wire.WriteUint32(w, uint32(int32(in.VOut)))
+	if err != nil {
+		return err
+	}
+	err = wire.WriteVarBytes(w, in.ScriptSig)
+	if err != nil {
+		return err
+	}
+	return wire.WriteUint32(w, in.Sequence)
 }
 
+// Decode reads an input written by Encode.
+func (in *TXInput) Decode(r io.Reader) error {
+	var err error
+	in.PreviousTx, err = wire.ReadVarBytes(r)
+	if err != nil {
+		return err
+	}
+	vOut, err := wire.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	in.VOut = int(int32(vOut))
+	in.ScriptSig, err = wire.ReadVarBytes(r)
+	if err != nil {
+		return err
+	}
+	in.Sequence, err = wire.ReadUint32(r)
+	return err
+}
@@ -17,27 +17,70 @@ package tx_io
 
 import (
 	"bytes"
+	"io"
+	"log"
 
-	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/base58"
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
 )
 
+// TXOutput holds a value which can be spent by an input satisfying
+// conditions of ScriptPubKey.
 type TXOutput struct {
-	Value      float64
-	PubKeyHash []byte
+	Value        money.Amount
+	ScriptPubKey []byte
 }
 
+// Lock sets a script paying to given address of the active network:
+// a pay-to-script-hash one for script hash addresses and a pay-to-pubkey-hash
+// one otherwise.
 func (out *TXOutput) Lock(address []byte) {
-	pubKeyHash := base58.Decode(address)
-	pubKeyHash = pubKeyHash[1: len(pubKeyHash)-4]
-	out.PubKeyHash = pubKeyHash
+	codec := wallet.ActiveAddressCodec()
+	version, hash, err := codec.Decode(string(address))
+	if err != nil {
+		log.Panic(err)
+	}
+	var scriptPubKey []byte
+	if version == codec.ScriptHashAddrID {
+		scriptPubKey, err = script.PayToScriptHashScript(hash)
+	} else {
+		scriptPubKey, err = script.PayToPubKeyHashScript(hash)
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+	out.ScriptPubKey = scriptPubKey
 }
 
+// IsLockedWithKey checks if the output is a pay-to-pubkey-hash one for given key hash.
 func (out TXOutput) IsLockedWithKey(pubKeyHash []byte) bool {
-	return bytes.Compare(out.PubKeyHash, pubKeyHash) == 0
+	return bytes.Compare(script.ExtractPubKeyHash(out.ScriptPubKey), pubKeyHash) == 0
 }
 
-func NewTXOutput(value float64, address string) TXOutput {
+func NewTXOutput(value money.Amount, address string) TXOutput {
 	txo := TXOutput{value, nil}
 	txo.Lock([]byte(address))
 	return txo
 }
+
+// Encode writes the output in the wire format.
+func (out TXOutput) Encode(w io.Writer) error {
+	err := wire.WriteUint64(w, uint64(out.Value))
+	if err != nil {
+		return err
+	}
+	return wire.WriteVarBytes(w, out.ScriptPubKey)
+}
+
+// Decode reads an output written by Encode.
+func (out *TXOutput) Decode(r io.Reader) error {
+	value, err := wire.ReadUint64(r)
+	if err != nil {
+		return err
+	}
+	out.Value = money.Amount(value)
+	out.ScriptPubKey, err = wire.ReadVarBytes(r)
+	return err
+}
@@ -15,8 +15,37 @@
 
 package tx_io
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+)
 
 func Test(test *testing.T) {
 
 }
+
+func TestTXOutput_Lock(test *testing.T) {
+	codec := wallet.ActiveAddressCodec()
+	hash := bytes.Repeat([]byte{0x42}, 20)
+	data := []struct {
+		name     string
+		address  []byte
+		expected script.ScriptClass
+	}{
+		{"pay-to-pubkey-hash", codec.EncodePubKeyHash(hash), script.PUB_KEY_HASH},
+		{"pay-to-script-hash", codec.EncodeScriptHash(hash), script.SCRIPT_HASH},
+	}
+	for _, d := range data {
+		out := NewTXOutput(1, string(d.address))
+		if class := script.GetScriptClass(out.ScriptPubKey); class != d.expected {
+			test.Errorf("tx_io.TestTXOutput_Lock, %s:\nactual:\n%s\nexpected:\n%s", d.name, class, d.expected)
+		}
+	}
+	out := NewTXOutput(1, string(codec.EncodeScriptHash(hash)))
+	if actual := script.ExtractScriptHash(out.ScriptPubKey); !bytes.Equal(actual, hash) {
+		test.Errorf("tx_io.TestTXOutput_Lock, script hash:\nactual:\n%x\nexpected:\n%x", actual, hash)
+	}
+}
@@ -17,18 +17,88 @@ package tx_io
 
 import (
 	"bytes"
-	"encoding/gob"
+	"io"
 	"log"
+	"sort"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
 )
 
+// TXOutputs holds unspent outputs of a single transaction keyed by
+// their index in the transaction, so spending one of them does not
+// shift the others. Height is the height of the block which includes
+// the transaction.
 type TXOutputs struct {
-	Outputs []TXOutput
+	Height   int
+	CoinBase bool
+	Outputs  map[int]TXOutput
+}
+
+// Encode writes the outputs in the wire format: the height and coin base flag
+// packed as height*2+flag, the number of outputs followed by index and value
+// of each output in ascending order of indexes.
+func (outs TXOutputs) Encode(w io.Writer) error {
+	var indexes []int
+	for idx := range outs.Outputs {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	code := uint64(outs.Height) << 1
+	if outs.CoinBase {
+		code |= 1
+	}
+	err := wire.WriteVarInt(w, code)
+	if err != nil {
+		return err
+	}
+	err = wire.WriteVarInt(w, uint64(len(indexes)))
+	if err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		err = wire.WriteVarInt(w, uint64(idx))
+		if err != nil {
+			return err
+		}
+		err = outs.Outputs[idx].Encode(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode reads outputs written by Encode.
+func (outs *TXOutputs) Decode(r io.Reader) error {
+	code, err := wire.ReadVarInt(r)
+	if err != nil {
+		return err
+	}
+	outs.Height = int(code >> 1)
+	outs.CoinBase = code&1 != 0
+	count, err := wire.ReadCount(r)
+	if err != nil {
+		return err
+	}
+	outs.Outputs = make(map[int]TXOutput)
+	for i := 0; i < count; i++ {
+		idx, err := wire.ReadCount(r)
+		if err != nil {
+			return err
+		}
+		var out TXOutput
+		err = out.Decode(r)
+		if err != nil {
+			return err
+		}
+		outs.Outputs[idx] = out
+	}
+	return nil
 }
 
 func (outs TXOutputs) Serialize() []byte {
 	var buff bytes.Buffer
-	enc := gob.NewEncoder(&buff)
-	err := enc.Encode(outs)
+	err := outs.Encode(&buff)
 	if err != nil {
 		log.Panic(err)
 	}
@@ -37,8 +107,11 @@ func (outs TXOutputs) Serialize() []byte {
 
 func DeserializeOutputs(data []byte) TXOutputs {
 	var outputs TXOutputs
-	dec := gob.NewDecoder(bytes.NewReader(data))
-	err := dec.Decode(&outputs)
+	r := bytes.NewReader(data)
+	err := outputs.Decode(r)
+	if err == nil && r.Len() != 0 {
+		err = wire.ErrTrailingData
+	}
 	if err != nil {
 		log.Panic(err)
 	}
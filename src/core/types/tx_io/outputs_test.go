@@ -14,3 +14,29 @@
 // along with this program. If not, see <http://www.gnu.org/licenses/>.
 
 package tx_io
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTXOutputs_Serialize(test *testing.T) {
+	expected := TXOutputs{Height: 120, CoinBase: true, Outputs: map[int]TXOutput{
+		0: {Value: 1, ScriptPubKey: []byte("a")},
+		3: {Value: 2, ScriptPubKey: []byte("b")},
+		7: {Value: 3, ScriptPubKey: []byte("c")},
+	}}
+	data := expected.Serialize()
+	actual := DeserializeOutputs(data)
+	if !reflect.DeepEqual(actual, expected) {
+		test.Errorf("tx_io.TestTXOutputs_Serialize:\nactual:\n%v\nexpected:\n%v", actual, expected)
+	}
+
+	// Map iteration order must not affect the encoding.
+	for i := 0; i < 10; i++ {
+		if !bytes.Equal(expected.Serialize(), data) {
+			test.Errorf("tx_io.TestTXOutputs_Serialize: encoding is not deterministic")
+		}
+	}
+}
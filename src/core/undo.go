@@ -0,0 +1,60 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/gob"
+	"log"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+)
+
+// SpentOutput is an output which was spent by a connected block.
+// Height is the height of the block which created the output.
+type SpentOutput struct {
+	PreviousTx []byte
+	VOut       int
+	Output     tx_io.TXOutput
+	Height     int
+	CoinBase   bool
+}
+
+// BlockUndo holds outputs spent by a block in the order they were spent,
+// so the block can be disconnected from the UTXO set.
+type BlockUndo struct {
+	SpentOutputs []SpentOutput
+}
+
+func (u BlockUndo) Serialize() []byte {
+	var result bytes.Buffer
+	encoder := gob.NewEncoder(&result)
+	err := encoder.Encode(u)
+	if err != nil {
+		log.Panic(err)
+	}
+	return result.Bytes()
+}
+
+func DeserializeBlockUndo(data []byte) BlockUndo {
+	var undo BlockUndo
+	decoder := gob.NewDecoder(bytes.NewReader(data))
+	err := decoder.Decode(&undo)
+	if err != nil {
+		log.Panic(err)
+	}
+	return undo
+}
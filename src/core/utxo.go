@@ -16,24 +16,36 @@
 package core
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
 
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
 	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
 )
 
 type UTXOSet struct {
 	BlockChain BlockChain
 }
 
-func (u UTXOSet) FindSpendableOutputs(pubkeyHash []byte, amount float64) (float64, map[string][]int) {
+// Coin is an unspent output along with the height of the block which
+// created it and whether it was created by a coin base transaction.
+type Coin struct {
+	Output   tx_io.TXOutput
+	Height   int
+	CoinBase bool
+}
+
+func (u UTXOSet) FindSpendableOutputs(pubkeyHash []byte, amount money.Amount) (money.Amount, map[string][]int) {
 	unspentOutputs := make(map[string][]int)
-	accumulated := float64(0)
+	accumulated := money.Amount(0)
 	db := u.BlockChain.db
 	err := db.View(func(tx *db_pkg.Tx) error {
 		b := tx.Bucket(vars.UTXO_BUCKET)
@@ -104,87 +116,319 @@ func (u UTXOSet) CountTransactions() int {
 	return counter
 }
 
-func (u UTXOSet) Reindex() {
-	db := u.BlockChain.db
-	err := db.Batch(func(tx *db_pkg.Tx) error {
-		err := tx.DeleteBucket(vars.UTXO_BUCKET)
-		if err != nil && err != db_pkg.ErrBucketNotFound {
-			log.Panic(err)
+// TxOutSetInfo holds statistics of the UTXO set at the tip of the main chain.
+type TxOutSetInfo struct {
+	Height       int
+	BestBlock    []byte
+	Transactions int
+	Outputs      int
+	TotalAmount  money.Amount
+	TotalSubsidy money.Amount
+}
+
+// GetTxOutSetInfo calculates statistics of the UTXO set. TotalAmount is the
+// value of unspent outputs, TotalSubsidy is the supply issued by the blocks;
+// they differ by unclaimed rewards and burnt outputs.
+func (u UTXOSet) GetTxOutSetInfo() (TxOutSetInfo, error) {
+	var info TxOutSetInfo
+	err := u.BlockChain.db.View(func(tx *db_pkg.Tx) error {
+		b := tx.Bucket(vars.UTXO_BUCKET)
+		if b == nil {
+			return errors.New(fmt.Sprintf("bucket '%x' does not exist", vars.UTXO_BUCKET))
 		}
-		_, err = tx.CreateBucket(vars.UTXO_BUCKET)
+		info.BestBlock = append([]byte{}, tx.Bucket(utils.BLOCKS_BUCKET).Get(utils.LAST_BLOCK_HASH)...)
+		tipEntry, err := getBlockIndexEntry(tx.Bucket(utils.BLOCK_INDEX_BUCKET), info.BestBlock)
 		if err != nil {
-			log.Panic(err)
+			return err
 		}
-		return nil
+		info.Height = tipEntry.Height
+		return b.ForEach(func(k, v []byte) error {
+			outs := tx_io.DeserializeOutputs(v)
+			info.Transactions++
+			for _, out := range outs.Outputs {
+				info.Outputs++
+				info.TotalAmount, err = money.Sum(info.TotalAmount, out.Value)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	})
 	if err != nil {
-		log.Panic(err)
+		return info, err
 	}
-	UTXO := u.BlockChain.FindUTXO()
-	err = db.Batch(func(tx *db_pkg.Tx) error {
-		b := tx.Bucket(vars.UTXO_BUCKET)
-		if b == nil {
-			return errors.New(fmt.Sprintf("bucket '%x' does not exist", vars.UTXO_BUCKET))
+	info.TotalSubsidy = GetTotalSubsidy(info.Height)
+	return info, nil
+}
+
+// Reindex rebuilds the UTXO set, undo records, the height index and optional
+// indexes which are enabled by connecting all blocks of the main chain
+// starting from the genesis block.
+func (u UTXOSet) Reindex() {
+	var hashes [][]byte
+	bci := u.BlockChain.Iterator()
+	for !bci.End() {
+		hashes = append(hashes, bci.Next().Hash)
+	}
+	db := u.BlockChain.db
+	err := db.Update(func(tx *db_pkg.Tx) error {
+		buckets := [][]byte{vars.UTXO_BUCKET, vars.UNDO_BUCKET, utils.HEIGHT_INDEX_BUCKET}
+		for _, bucket := range [][]byte{utils.TX_INDEX_BUCKET, utils.ADDR_INDEX_BUCKET} {
+			if tx.Bucket(bucket) != nil {
+				buckets = append(buckets, bucket)
+			}
 		}
-		for txID, outs := range UTXO {
-			key, err := hex.DecodeString(txID)
+		for _, bucket := range buckets {
+			err := tx.DeleteBucket(bucket)
+			if err != nil && err != db_pkg.ErrBucketNotFound {
+				return err
+			}
+			_, err = tx.CreateBucket(bucket)
 			if err != nil {
-				log.Panic(err)
+				return err
 			}
-			err = b.Put(key, outs.Serialize())
+		}
+		blocks := tx.Bucket(utils.BLOCKS_BUCKET)
+		for i := len(hashes) - 1; i >= 0; i-- {
+			err := u.connect(tx, DeserializeBlock(blocks.Get(hashes[i])))
 			if err != nil {
-				log.Panic(err)
+				return err
 			}
 		}
 		return nil
 	})
+	if err != nil {
+		log.Panic(err)
+	}
 }
 
 func (u UTXOSet) Update(block types.Block) {
 	db := u.BlockChain.db
 	vars.DBMutex.Lock()
 	err := db.Batch(func(tx *db_pkg.Tx) error {
-		b := tx.Bucket([]byte(vars.UTXO_BUCKET))
+		return u.connect(tx, block)
+	})
+	vars.DBMutex.Unlock()
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// Disconnect reverts given block's transactions in the UTXO set using
+// the undo record which was stored when the block was connected.
+func (u UTXOSet) Disconnect(block types.Block) {
+	db := u.BlockChain.db
+	vars.DBMutex.Lock()
+	err := db.Batch(func(tx *db_pkg.Tx) error {
+		return u.disconnect(tx, block)
+	})
+	vars.DBMutex.Unlock()
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// FindOutput looks up an unspent output by the hash of its transaction and
+// its index. Returns false if the output does not exist or is already spent.
+func (u UTXOSet) FindOutput(txHash []byte, outIdx int) (tx_io.TXOutput, bool) {
+	coin, found := u.FindCoin(txHash, outIdx)
+	return coin.Output, found
+}
+
+// FindCoin is like FindOutput, but also returns the height and the origin of the output.
+func (u UTXOSet) FindCoin(txHash []byte, outIdx int) (Coin, bool) {
+	var coin Coin
+	found := false
+	err := u.BlockChain.db.View(func(tx *db_pkg.Tx) error {
+		b := tx.Bucket(vars.UTXO_BUCKET)
 		if b == nil {
 			return errors.New(fmt.Sprintf("bucket '%x' does not exist", vars.UTXO_BUCKET))
 		}
-		for _, tx := range block.Transactions {
-			if tx.IsCoinBase() == false {
-				for _, vin := range tx.VIn {
-					updatedOuts := tx_io.TXOutputs{}
-					outsBytes := b.Get(vin.PreviousTx)
-					outs := tx_io.DeserializeOutputs(outsBytes)
-					for outIdx, out := range outs.Outputs {
-						if outIdx != vin.VOut {
-							updatedOuts.Outputs = append(updatedOuts.Outputs, out)
-						}
+		coin, found = findCoin(b, txHash, outIdx)
+		return nil
+	})
+	if err != nil {
+		log.Panic(err)
+	}
+	return coin, found
+}
+
+func findCoin(b *db_pkg.Bucket, txHash []byte, outIdx int) (Coin, bool) {
+	outsBytes := b.Get(txHash)
+	if outsBytes == nil {
+		return Coin{}, false
+	}
+	outs := tx_io.DeserializeOutputs(outsBytes)
+	out, found := outs.Outputs[outIdx]
+	return Coin{Output: out, Height: outs.Height, CoinBase: outs.CoinBase}, found
+}
+
+// connect applies block's transactions to the UTXO set within given
+// database transaction: spent outputs are removed, new ones are added.
+// Spent outputs are saved to the block's undo record and the block is
+// added to the height index and, if enabled, to the transaction and address
+// indexes.
+func (u UTXOSet) connect(dbTx *db_pkg.Tx, block types.Block) error {
+	b := dbTx.Bucket(vars.UTXO_BUCKET)
+	if b == nil {
+		return errors.New(fmt.Sprintf("bucket '%x' does not exist", vars.UTXO_BUCKET))
+	}
+	undoBucket, err := dbTx.CreateBucketIfNotExists(vars.UNDO_BUCKET)
+	if err != nil {
+		return err
+	}
+	heights, err := dbTx.CreateBucketIfNotExists(utils.HEIGHT_INDEX_BUCKET)
+	if err != nil {
+		return err
+	}
+	err = heights.Put(heightKey(block.Height), block.Hash)
+	if err != nil {
+		return err
+	}
+	if txIndex := dbTx.Bucket(utils.TX_INDEX_BUCKET); txIndex != nil {
+		err = indexTransactions(txIndex, block)
+		if err != nil {
+			return err
+		}
+	}
+	undo := BlockUndo{}
+	for _, tx := range block.Transactions {
+		if tx.IsCoinBase() == false {
+			for _, vin := range tx.VIn {
+				outsBytes := b.Get(vin.PreviousTx)
+				if outsBytes == nil {
+					return ErrMissingInputs
+				}
+				outs := tx_io.DeserializeOutputs(outsBytes)
+				out, ok := outs.Outputs[vin.VOut]
+				if !ok {
+					return ErrMissingInputs
+				}
+				undo.SpentOutputs = append(undo.SpentOutputs, SpentOutput{
+					PreviousTx: vin.PreviousTx,
+					VOut:       vin.VOut,
+					Output:     out,
+					Height:     outs.Height,
+					CoinBase:   outs.CoinBase,
+				})
+				delete(outs.Outputs, vin.VOut)
+				if len(outs.Outputs) == 0 {
+					err := b.Delete(vin.PreviousTx)
+					if err != nil {
+						return err
 					}
-					if len(updatedOuts.Outputs) == 0 {
-						err := b.Delete(vin.PreviousTx)
-						if err != nil {
-							log.Panic(err)
-						}
-					} else {
-						err := b.Put(vin.PreviousTx, updatedOuts.Serialize())
-						if err != nil {
-							log.Panic(err)
-						}
+				} else {
+					err := b.Put(vin.PreviousTx, outs.Serialize())
+					if err != nil {
+						return err
 					}
 				}
 			}
-			newOutputs := tx_io.TXOutputs{}
-			for _, out := range tx.VOut {
-				newOutputs.Outputs = append(newOutputs.Outputs, out)
-			}
-			err := b.Put(tx.Hash, newOutputs.Serialize())
-			if err != nil {
-				log.Panic(err)
+		}
+		newOutputs := tx_io.TXOutputs{
+			Height:   block.Height,
+			CoinBase: tx.IsCoinBase(),
+			Outputs:  make(map[int]tx_io.TXOutput),
+		}
+		for outIdx, out := range tx.VOut {
+
+			// Provably unspendable outputs are never added to the set.
+			if script.IsUnspendable(out.ScriptPubKey) {
+				continue
 			}
+			newOutputs.Outputs[outIdx] = out
+		}
+		if len(newOutputs.Outputs) == 0 {
+			continue
+		}
+		err := b.Put(tx.Hash, newOutputs.Serialize())
+		if err != nil {
+			return err
+		}
+	}
+	if addrIndex := dbTx.Bucket(utils.ADDR_INDEX_BUCKET); addrIndex != nil {
+		err = indexAddresses(addrIndex, block, undo.SpentOutputs)
+		if err != nil {
+			return err
+		}
+	}
+	return undoBucket.Put(block.Hash, undo.Serialize())
+}
+
+// disconnect reverts block's transactions in the UTXO set within given database
+// transaction: outputs created by the block are removed and outputs spent by it
+// are restored from the block's undo record. The block is removed from the
+// height index and the transaction and address indexes.
+func (u UTXOSet) disconnect(dbTx *db_pkg.Tx, block types.Block) error {
+	b := dbTx.Bucket(vars.UTXO_BUCKET)
+	if b == nil {
+		return errors.New(fmt.Sprintf("bucket '%x' does not exist", vars.UTXO_BUCKET))
+	}
+	undoBucket := dbTx.Bucket(vars.UNDO_BUCKET)
+	if undoBucket == nil {
+		return ErrMissingUndoData
+	}
+	undoBytes := undoBucket.Get(block.Hash)
+	if undoBytes == nil {
+		return ErrMissingUndoData
+	}
+	heights := dbTx.Bucket(utils.HEIGHT_INDEX_BUCKET)
+	if heights != nil {
+		err := heights.Delete(heightKey(block.Height))
+		if err != nil {
+			return err
+		}
+	}
+	if txIndex := dbTx.Bucket(utils.TX_INDEX_BUCKET); txIndex != nil {
+		err := unindexTransactions(txIndex, block)
+		if err != nil {
+			return err
+		}
+	}
+	undo := DeserializeBlockUndo(undoBytes)
+	if addrIndex := dbTx.Bucket(utils.ADDR_INDEX_BUCKET); addrIndex != nil {
+		err := unindexAddresses(addrIndex, block, undo.SpentOutputs)
+		if err != nil {
+			return err
+		}
+	}
+	for _, tx := range block.Transactions {
+		err := b.Delete(tx.Hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Outputs created by the block itself were removed above,
+	// only outputs of earlier blocks are restored.
+	for i := len(undo.SpentOutputs) - 1; i >= 0; i-- {
+		spent := undo.SpentOutputs[i]
+		if _, found := findTransactionInBlock(block, spent.PreviousTx); found {
+			continue
+		}
+		outs := tx_io.TXOutputs{
+			Height:   spent.Height,
+			CoinBase: spent.CoinBase,
+			Outputs:  make(map[int]tx_io.TXOutput),
+		}
+		if outsBytes := b.Get(spent.PreviousTx); outsBytes != nil {
+			outs = tx_io.DeserializeOutputs(outsBytes)
+		}
+		outs.Outputs[spent.VOut] = spent.Output
+		err := b.Put(spent.PreviousTx, outs.Serialize())
+		if err != nil {
+			return err
+		}
+	}
+	return undoBucket.Delete(block.Hash)
+}
+
+func findTransactionInBlock(block types.Block, txHash []byte) (types.Transaction, bool) {
+	for _, tx := range block.Transactions {
+		if bytes.Compare(tx.Hash, txHash) == 0 {
+			return tx, true
 		}
-		return nil
-	})
-	vars.DBMutex.Unlock()
-	if err != nil {
-		log.Panic(err)
 	}
+	return types.Transaction{}, false
 }
@@ -0,0 +1,151 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+func newTestTx(hash string, vin []tx_io.TXInput, values ...money.Amount) types.Transaction {
+	tx := types.Transaction{Hash: []byte(hash), VIn: vin}
+	for _, value := range values {
+		tx.VOut = append(tx.VOut, tx_io.TXOutput{Value: value, ScriptPubKey: []byte(hash)})
+	}
+	return tx
+}
+
+// newTestBlockChain opens a block chain database in a temporary directory
+// and creates given buckets in it. The returned function closes and removes
+// the database.
+func newTestBlockChain(test *testing.T, buckets ...[]byte) (BlockChain, func()) {
+	dir, err := ioutil.TempDir("", "chain")
+	if err != nil {
+		test.Fatal(err)
+	}
+	db, err := db_pkg.Open(filepath.Join(dir, "chain.db"), 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		test.Fatal(err)
+	}
+	cleanup := func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+	err = db.Update(func(tx *db_pkg.Tx) error {
+		for _, bucket := range buckets {
+			_, err := tx.CreateBucket(bucket)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		cleanup()
+		test.Fatal(err)
+	}
+	return BlockChain{db: db}, cleanup
+}
+
+// newTestGenesisChain opens a block chain database like newTestBlockChain
+// and stores the genesis block of the active network in it.
+func newTestGenesisChain(test *testing.T) (BlockChain, func()) {
+	bc, cleanup := newTestBlockChain(test, utils.BLOCK_INDEX_BUCKET)
+	genesis := NewGenesisBlock(params.Active())
+	entry := newBlockIndexEntry(genesis.BlockHeader, nil)
+	entry.Status |= BLOCK_HAVE_DATA
+	err := bc.db.PutArray(
+		[][]byte{genesis.Hash, utils.LAST_BLOCK_HASH},
+		[][]byte{genesis.Serialize(), genesis.Hash},
+		utils.BLOCKS_BUCKET, false,
+	)
+	if err == nil {
+		err = bc.db.Put(genesis.Hash, entry.Serialize(), utils.BLOCK_INDEX_BUCKET, false)
+	}
+	if err != nil {
+		cleanup()
+		test.Fatal(err)
+	}
+	bc.tip = genesis.Hash
+	UTXOSet{BlockChain: bc}.Reindex()
+	return bc, cleanup
+}
+
+func dumpUTXOSet(test *testing.T, db *db_pkg.DB) map[string]string {
+	state := make(map[string]string)
+	err := db.View(func(tx *db_pkg.Tx) error {
+		return tx.Bucket(vars.UTXO_BUCKET).ForEach(func(k, v []byte) error {
+			outs := tx_io.DeserializeOutputs(v)
+			for idx, out := range outs.Outputs {
+				state[outpointKey(k, idx)] = fmt.Sprintf("%s:%s", out.ScriptPubKey, out.Value)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		test.Fatal(err)
+	}
+	return state
+}
+
+func TestUTXOSet_Disconnect(test *testing.T) {
+	bc, cleanup := newTestBlockChain(test, vars.UTXO_BUCKET)
+	defer cleanup()
+	db := bc.db
+	coinBase := []tx_io.TXInput{{PreviousTx: []byte{}, VOut: -1}}
+	utxoSet := UTXOSet{BlockChain: bc}
+
+	block1 := types.Block{Hash: []byte("block1"), Transactions: []types.Transaction{
+		newTestTx("cb1", coinBase, 50, 25),
+	}}
+	utxoSet.Update(block1)
+	expected := dumpUTXOSet(test, db)
+
+	// The second block spends one output of the first one and
+	// an output created within the block itself.
+	block2 := types.Block{Hash: []byte("block2"), Transactions: []types.Transaction{
+		newTestTx("cb2", coinBase, 50),
+		newTestTx("tx1", []tx_io.TXInput{{PreviousTx: []byte("cb1"), VOut: 1}}, 20, 5),
+		newTestTx("tx2", []tx_io.TXInput{{PreviousTx: []byte("tx1"), VOut: 0}}, 20),
+	}}
+	utxoSet.Update(block2)
+	if _, found := utxoSet.FindOutput([]byte("cb1"), 1); found {
+		test.Error("core.TestUTXOSet_Disconnect: spent output is found in the UTXO set")
+	}
+
+	utxoSet.Disconnect(block2)
+	actual := dumpUTXOSet(test, db)
+	if len(actual) != len(expected) {
+		test.Errorf("core.TestUTXOSet_Disconnect, size:\nactual:\n%d\nexpected:\n%d", len(actual), len(expected))
+	}
+	for key, value := range expected {
+		if actual[key] != value {
+			test.Errorf("core.TestUTXOSet_Disconnect, output %s is not restored", key)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+)
+
+// utxoView is an in-memory overlay on top of the UTXO set. It allows
+// transactions of a block to spend outputs created earlier in the same
+// block without touching the database.
+type utxoView struct {
+	fetch  func(txHash []byte, outIdx int) (Coin, bool)
+	height int
+	txs    map[string]types.Transaction
+	spent  map[string]bool
+}
+
+// newUTXOView creates a view which reads outputs missing in memory with given
+// function. Outputs of transactions added to the view get given height.
+func newUTXOView(fetch func(txHash []byte, outIdx int) (Coin, bool), height int) *utxoView {
+	return &utxoView{
+		fetch:  fetch,
+		height: height,
+		txs:    make(map[string]types.Transaction),
+		spent:  make(map[string]bool),
+	}
+}
+
+// lookup returns an unspent output referenced by transaction hash and output index.
+func (v *utxoView) lookup(txHash []byte, outIdx int) (Coin, bool) {
+	if v.spent[outpointKey(txHash, outIdx)] {
+		return Coin{}, false
+	}
+	if tx, ok := v.txs[hex.EncodeToString(txHash)]; ok {
+		if outIdx < 0 || outIdx >= len(tx.VOut) {
+			return Coin{}, false
+		}
+		return Coin{Output: tx.VOut[outIdx], Height: v.height, CoinBase: tx.IsCoinBase()}, true
+	}
+	return v.fetch(txHash, outIdx)
+}
+
+// isSpent checks if the output is spent by a transaction added to the view.
+func (v *utxoView) isSpent(txHash []byte, outIdx int) bool {
+	return v.spent[outpointKey(txHash, outIdx)]
+}
+
+// addTransaction marks outputs spent by given transaction and makes its own outputs available.
+func (v *utxoView) addTransaction(tx types.Transaction) {
+	if !tx.IsCoinBase() {
+		for _, vin := range tx.VIn {
+			v.spent[outpointKey(vin.PreviousTx, vin.VOut)] = true
+		}
+	}
+	v.txs[hex.EncodeToString(tx.Hash)] = tx
+}
+
+func outpointKey(txHash []byte, outIdx int) string {
+	return fmt.Sprintf("%x:%d", txHash, outIdx)
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+	db_pkg "github.com/YuriyLisovskiy/blockchain-go/src/db"
+	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
+)
+
+// ValidateBlock checks if given block can be written to the database.
+// It verifies block's structure and proof of work, its linkage to the previous
+// block and, if the block extends the current tip, all its transactions
+// against the UTXO set.
+func (bc *BlockChain) ValidateBlock(block types.Block) error {
+	err := checkBlockSanity(block)
+	if err != nil {
+		return err
+	}
+	prevEntry, err := bc.GetBlockIndexEntry(block.PrevBlockHash)
+	if err == db_pkg.ErrKeyNotFound {
+		return ErrPrevBlockNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Transactions of the previous block are required to connect this one.
+	if !prevEntry.HaveData() {
+		return ErrPrevBlockNotFound
+	}
+	if block.Height != prevEntry.Height+1 {
+		return ErrBadHeight
+	}
+	err = bc.checkBlockHeaderContext(block.BlockHeader, prevEntry)
+	if err != nil {
+		return err
+	}
+	tip, err := bc.db.Get(utils.LAST_BLOCK_HASH, utils.BLOCKS_BUCKET)
+	if err != nil {
+		return err
+	}
+
+	// Transactions of a side chain block can not be checked against the
+	// UTXO set, which represents the state of the current tip. They are
+	// checked if the side chain becomes the main one.
+	if bytes.Equal(block.PrevBlockHash, tip) {
+		ctx, err := newLockContext(prevEntry, bc.GetBlockIndexEntry)
+		if err != nil {
+			return err
+		}
+		view := newUTXOView(UTXOSet{BlockChain: *bc}.FindCoin, block.Height)
+		return checkBlockTransactions(block, view, ctx)
+	}
+	return nil
+}
+
+// ValidateBlockHeader checks if given header can be added to the block index
+// without block's transactions.
+func (bc *BlockChain) ValidateBlockHeader(header types.BlockHeader) error {
+	err := checkBlockHeaderSanity(header)
+	if err != nil {
+		return err
+	}
+	prevEntry, err := bc.GetBlockIndexEntry(header.PrevBlockHash)
+	if err == db_pkg.ErrKeyNotFound {
+		return ErrPrevBlockNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return bc.checkBlockHeaderContext(header, prevEntry)
+}
+
+// checkBlockHeaderSanity checks header's proof of work.
+func checkBlockHeaderSanity(header types.BlockHeader) error {
+	worker := NewProofOfWork(header)
+	if !worker.Validate() {
+		return ErrHighHash
+	}
+	return nil
+}
+
+// checkBlockHeaderContext checks header against its parent.
+func (bc *BlockChain) checkBlockHeaderContext(header types.BlockHeader, prevEntry BlockIndexEntry) error {
+	if prevEntry.Failed() {
+		return ErrBadPrevBlock
+	}
+	bits, err := bc.calcNextRequiredBits(prevEntry)
+	if err != nil {
+		return err
+	}
+	if header.Bits != bits {
+		return ErrBadDiffBits
+	}
+	medianTime, err := medianTimePast(prevEntry, bc.GetBlockIndexEntry)
+	if err != nil {
+		return err
+	}
+	return checkBlockTime(header, medianTime, GetAdjustedTime())
+}
+
+// checkBlockTime checks that the header's timestamp is above median time
+// past of the previous block and not too far ahead of adjusted time.
+func checkBlockTime(header types.BlockHeader, medianTime, adjustedTime int64) error {
+	if header.Timestamp <= medianTime {
+		return ErrTimeTooOld
+	}
+	if header.Timestamp > adjustedTime+maxFutureBlockTime {
+		return ErrTimeTooNew
+	}
+	return nil
+}
+
+// checkBlockSanity performs checks which do not depend on the chain state.
+func checkBlockSanity(block types.Block) error {
+	if len(block.Transactions) == 0 {
+		return ErrNoTransactions
+	}
+	if len(block.Serialize()) > vars.MAX_BLOCK_SIZE {
+		return ErrBlockTooLarge
+	}
+	if !block.Transactions[0].IsCoinBase() {
+		return ErrFirstTxNotCoinBase
+	}
+	for _, tx := range block.Transactions[1:] {
+		if tx.IsCoinBase() {
+			return ErrMultipleCoinBases
+		}
+	}
+
+	// Block hash commits to the merkle root of block's transactions,
+	// so checking both of them checks transactions as well.
+	tree := block.MerkleTree()
+	if !bytes.Equal(tree.Root(), block.MerkleRoot) {
+		return ErrBadMerkleRoot
+	}
+
+	// Duplicated transactions do not change the root, so a mutated
+	// block has the same hash as the valid one it was derived from.
+	if tree.Mutated {
+		return ErrDuplicateTx
+	}
+	if !bytes.Equal(block.BlockHeader.Hash(), block.Hash) {
+		return ErrBadBlockHash
+	}
+	err := checkBlockHeaderSanity(block.BlockHeader)
+	if err != nil {
+		return err
+	}
+	sigOps := 0
+	for _, tx := range block.Transactions {
+		if !bytes.Equal(tx.Hash, tx.CalcHash()) {
+			return ErrBadTxHash
+		}
+		sigOps += getLegacySigOpCount(tx)
+	}
+	if sigOps > vars.MAX_BLOCK_SIGOPS {
+		return ErrTooManySigOps
+	}
+	return nil
+}
+
+// checkBlockTransactions verifies block's transactions against given view
+// and lock context, and checks that the coin base starts with the block
+// height and does not pay more than reward plus fees. Signature checks of
+// redeem scripts are counted towards the block limit as well.
+func checkBlockTransactions(block types.Block, view *utxoView, ctx lockContext) error {
+	err := checkCoinBaseHeight(block.Transactions[0], ctx.height)
+	if err != nil {
+		return err
+	}
+	coinBaseValue, err := checkTxOutputs(block.Transactions[0])
+	if err != nil {
+		return err
+	}
+	for _, tx := range block.Transactions {
+
+		// A transaction must not replace one with the same hash which
+		// still has unspent outputs, these would be lost.
+		for i := range tx.VOut {
+			if _, ok := view.lookup(tx.Hash, i); ok {
+				return ErrOverwriteTx
+			}
+		}
+	}
+	err = checkTxLocks(block.Transactions[0], nil, ctx)
+	if err != nil {
+		return err
+	}
+	view.addTransaction(block.Transactions[0])
+	fees := money.Amount(0)
+	sigOps := getLegacySigOpCount(block.Transactions[0])
+	for _, tx := range block.Transactions[1:] {
+		sigOps += getTxSigOpCount(tx, view)
+		if sigOps > vars.MAX_BLOCK_SIGOPS {
+			return ErrTooManySigOps
+		}
+		fee, err := checkTransaction(tx, view, ctx)
+		if err != nil {
+			return err
+		}
+		view.addTransaction(tx)
+		fees, err = money.Sum(fees, fee)
+		if err != nil {
+			return ErrFeesOutOfRange
+		}
+	}
+	maxCoinBaseValue, err := money.Sum(GetBlockSubsidy(block.Height), fees)
+	if err != nil || coinBaseValue > maxCoinBaseValue {
+		return ErrBadCoinBaseAmount
+	}
+	return nil
+}
+
+// checkCoinBaseHeight checks that the script of the coin base input starts
+// with the push of the block height, which makes coin bases of different
+// blocks distinct.
+func checkCoinBaseHeight(coinBase types.Transaction, height int) error {
+	expected, err := script.NewBuilder().AddInt64(int64(height)).Script()
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(coinBase.VIn[0].ScriptSig, expected) {
+		return ErrBadCoinBaseHeight
+	}
+	return nil
+}
+
+// checkTransaction verifies a non-coin base transaction against given view
+// and lock context, and returns the fee it pays. Each input must spend
+// a distinct output, which is not spent by transactions added to the view
+// and, if created by a coin base, is mature.
+func checkTransaction(tx types.Transaction, view *utxoView, ctx lockContext) (money.Amount, error) {
+	if len(tx.VIn) == 0 {
+		return 0, ErrNoTxInputs
+	}
+	if len(tx.VOut) == 0 {
+		return 0, ErrNoTxOutputs
+	}
+	if !bytes.Equal(tx.Hash, tx.CalcHash()) {
+		return 0, ErrBadTxHash
+	}
+	outValue, err := checkTxOutputs(tx)
+	if err != nil {
+		return 0, err
+	}
+	spent := make(map[string]bool)
+	for _, vin := range tx.VIn {
+		key := outpointKey(vin.PreviousTx, vin.VOut)
+		if spent[key] {
+			return 0, ErrDuplicateInputs
+		}
+		spent[key] = true
+	}
+	var prevOuts []tx_io.TXOutput
+	var coinHeights []int
+	inValue := money.Amount(0)
+	for _, vin := range tx.VIn {
+		if view.isSpent(vin.PreviousTx, vin.VOut) {
+			return 0, ErrDoubleSpend
+		}
+		coin, ok := view.lookup(vin.PreviousTx, vin.VOut)
+		if !ok {
+			return 0, ErrMissingInputs
+		}
+		if coin.CoinBase && ctx.height-coin.Height < vars.COINBASE_MATURITY {
+			return 0, ErrPrematureSpend
+		}
+		inValue, err = money.Sum(inValue, coin.Output.Value)
+		if err != nil {
+			return 0, ErrInputValuesOutOfRange
+		}
+		prevOuts = append(prevOuts, coin.Output)
+		coinHeights = append(coinHeights, coin.Height)
+	}
+	err = checkTxLocks(tx, coinHeights, ctx)
+	if err != nil {
+		return 0, err
+	}
+	if !tx.Verify(prevOuts) {
+		return 0, ErrBadSignature
+	}
+	if inValue < outValue {
+		return 0, ErrInputsBelowOutputs
+	}
+	return inValue - outValue, nil
+}
+
+// checkTxOutputs checks that values of transaction's outputs and their total
+// are within the money range and returns the total.
+func checkTxOutputs(tx types.Transaction) (money.Amount, error) {
+	total := money.Amount(0)
+	for _, out := range tx.VOut {
+		if out.Value < 0 {
+			return 0, ErrNegativeOutput
+		}
+		if out.Value > vars.MAX_MONEY {
+			return 0, ErrLargeOutput
+		}
+		var err error
+		total, err = money.Sum(total, out.Value)
+		if err != nil {
+			return 0, ErrLargeOutputTotal
+		}
+	}
+	return total, nil
+}
+
+// getLegacySigOpCount counts signature checks in scripts of the transaction
+// without looking at outputs it spends.
+func getLegacySigOpCount(tx types.Transaction) int {
+	count := 0
+	for _, vin := range tx.VIn {
+		count += script.GetSigOpCount(vin.ScriptSig, false)
+	}
+	for _, out := range tx.VOut {
+		count += script.GetSigOpCount(out.ScriptPubKey, false)
+	}
+	return count
+}
+
+// getTxSigOpCount counts all signature checks of the transaction including
+// the ones of redeem scripts spending pay-to-script-hash outputs in the view.
+// Inputs missing in the view are skipped.
+func getTxSigOpCount(tx types.Transaction, view *utxoView) int {
+	count := getLegacySigOpCount(tx)
+	if tx.IsCoinBase() {
+		return count
+	}
+	for _, vin := range tx.VIn {
+		if coin, ok := view.lookup(vin.PreviousTx, vin.VOut); ok {
+			count += script.GetScriptHashSigOpCount(vin.ScriptSig, coin.Output.ScriptPubKey)
+		}
+	}
+	return count
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/accounts/wallet"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/script"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/tx_io"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+)
+
+func newTestBlock(transactions []types.Transaction) types.Block {
+	block := types.Block{
+		BlockHeader: types.BlockHeader{
+			Version:       vars.BLOCK_VERSION,
+			PrevBlockHash: []byte{},
+			Timestamp:     1536000000,
+			Bits:          vars.POW_LIMIT_BITS,
+			Nonce:         0,
+		},
+		Transactions: transactions,
+		Height:       0,
+	}
+	if len(transactions) > 0 {
+		block.MerkleRoot = block.HashTransactions()
+		block.Hash = block.BlockHeader.Hash()
+	}
+	return block
+}
+
+func TestCheckBlockSanity(test *testing.T) {
+	address := string(wallet.NewWallet().GetAddress())
+	coinBase := NewCoinBaseTX(address, 0, 0)
+	tx := NewCoinBaseTX(address, 0, 0)
+	tx.VIn[0].VOut = 0
+	largeCoinBase := NewCoinBaseTX(address, 0, 0)
+	largeCoinBase.VOut[0].ScriptPubKey = make([]byte, vars.MAX_BLOCK_SIZE)
+	largeCoinBase.Hash = largeCoinBase.CalcHash()
+
+	data := []struct {
+		name     string
+		block    types.Block
+		expected error
+	}{
+		{"no transactions", newTestBlock(nil), ErrNoTransactions},
+		{"first is not coin base", newTestBlock([]types.Transaction{tx, coinBase}), ErrFirstTxNotCoinBase},
+		{"multiple coin bases", newTestBlock([]types.Transaction{coinBase, coinBase}), ErrMultipleCoinBases},
+		{"too large", newTestBlock([]types.Transaction{largeCoinBase}), ErrBlockTooLarge},
+	}
+	for _, d := range data {
+		actual := checkBlockSanity(d.block)
+		if actual != d.expected {
+			test.Errorf("core.TestCheckBlockSanity, %s:\nactual:\n%v\nexpected:\n%v", d.name, actual, d.expected)
+		}
+	}
+}
+
+func TestCheckBlockSanity_Hash(test *testing.T) {
+	address := string(wallet.NewWallet().GetAddress())
+	block := newTestBlock([]types.Transaction{NewCoinBaseTX(address, 0, 0)})
+
+	// Find a nonce which does not meet the target.
+	worker := NewProofOfWork(block.BlockHeader)
+	for worker.Validate() {
+		block.Nonce++
+		block.Hash = worker.CalcHash(block.Nonce)
+		worker = NewProofOfWork(block.BlockHeader)
+	}
+	if err := checkBlockSanity(block); err != ErrHighHash {
+		test.Errorf("core.TestCheckBlockSanity_Hash:\nactual:\n%v\nexpected:\n%v", err, ErrHighHash)
+	}
+
+	// Changing transactions must invalidate the merkle root.
+	block.Transactions[0].VOut[0].Value += 1
+	if err := checkBlockSanity(block); err != ErrBadMerkleRoot {
+		test.Errorf("core.TestCheckBlockSanity_Hash:\nactual:\n%v\nexpected:\n%v", err, ErrBadMerkleRoot)
+	}
+
+	// Changing the merkle root must invalidate the block's hash.
+	block.MerkleRoot = block.HashTransactions()
+	if err := checkBlockSanity(block); err != ErrBadBlockHash {
+		test.Errorf("core.TestCheckBlockSanity_Hash:\nactual:\n%v\nexpected:\n%v", err, ErrBadBlockHash)
+	}
+}
+
+// newTestSpend creates a transaction spending given outpoints, which are
+// locked to the wallet, with a single output of given value.
+func newTestSpend(test *testing.T, w *wallet.Wallet, prevOut tx_io.TXOutput, value money.Amount, outpoints ...string) types.Transaction {
+	tx := types.Transaction{
+		Version:   vars.TX_VERSION,
+		VOut:      []tx_io.TXOutput{{Value: value, ScriptPubKey: []byte("out")}},
+		Timestamp: 1536000000,
+	}
+	for _, outpoint := range outpoints {
+		tx.VIn = append(tx.VIn, tx_io.TXInput{PreviousTx: []byte(outpoint), VOut: 0, Sequence: vars.SEQUENCE_FINAL})
+	}
+	for i := range tx.VIn {
+		err := tx.SignInput(i, w.PrivateKey, w.PublicKey, prevOut, types.SIGHASH_ALL)
+		if err != nil {
+			test.Fatal(err)
+		}
+	}
+	tx.Hash = tx.CalcHash()
+	return tx
+}
+
+func TestCheckTransaction(test *testing.T) {
+	w := wallet.NewWallet()
+	scriptPubKey, err := script.PayToPubKeyHashScript(wallet.HashPubKey(w.PublicKey))
+	if err != nil {
+		test.Fatal(err)
+	}
+	prevOut := tx_io.TXOutput{Value: 100, ScriptPubKey: scriptPubKey}
+	coins := map[string]Coin{
+		"regular":  {Output: prevOut, Height: 10},
+		"coinbase": {Output: prevOut, Height: 10, CoinBase: true},
+	}
+	fetch := func(txHash []byte, outIdx int) (Coin, bool) {
+		coin, ok := coins[string(txHash)]
+		return coin, ok && outIdx == 0
+	}
+	data := []struct {
+		name     string
+		tx       types.Transaction
+		height   int
+		expected error
+	}{
+		{"valid", newTestSpend(test, w, prevOut, 90, "regular"), 20, nil},
+		{"duplicate inputs", newTestSpend(test, w, prevOut, 90, "regular", "regular"), 20, ErrDuplicateInputs},
+		{"missing input", newTestSpend(test, w, prevOut, 90, "missing"), 20, ErrMissingInputs},
+		{"immature coin base", newTestSpend(test, w, prevOut, 90, "coinbase"), 10 + vars.COINBASE_MATURITY - 1, ErrPrematureSpend},
+		{"mature coin base", newTestSpend(test, w, prevOut, 90, "coinbase"), 10 + vars.COINBASE_MATURITY, nil},
+		{"outputs above inputs", newTestSpend(test, w, prevOut, 101, "regular"), 20, ErrInputsBelowOutputs},
+	}
+	for _, d := range data {
+		view := newUTXOView(fetch, d.height)
+		_, actual := checkTransaction(d.tx, view, lockContext{height: d.height, medianTime: 1536000000})
+		if actual != d.expected {
+			test.Errorf("core.TestCheckTransaction, %s:\nactual:\n%v\nexpected:\n%v", d.name, actual, d.expected)
+		}
+	}
+
+	// Two transactions of a block can not spend the same output.
+	view := newUTXOView(fetch, 20)
+	ctx := lockContext{height: 20, medianTime: 1536000000}
+	first := newTestSpend(test, w, prevOut, 90, "regular")
+	if fee, err := checkTransaction(first, view, ctx); err != nil || fee != 10 {
+		test.Fatalf("core.TestCheckTransaction, double spend:\nactual:\n%v, %v\nexpected:\n10, <nil>", fee, err)
+	}
+	view.addTransaction(first)
+	second := newTestSpend(test, w, prevOut, 80, "regular")
+	if _, err := checkTransaction(second, view, ctx); err != ErrDoubleSpend {
+		test.Errorf("core.TestCheckTransaction, double spend:\nactual:\n%v\nexpected:\n%v", err, ErrDoubleSpend)
+	}
+}
+
+func TestCheckBlockTransactions_Subsidy(test *testing.T) {
+	address := string(wallet.NewWallet().GetAddress())
+	fetch := func(txHash []byte, outIdx int) (Coin, bool) {
+		return Coin{}, false
+	}
+	halving := vars.SUBSIDY_HALVING_INTERVAL
+	data := []struct {
+		name     string
+		height   int
+		subsidy  money.Amount
+		expected error
+	}{
+		{"genesis", 0, vars.MINING_REWARD, nil},
+		{"before halving", halving - 1, vars.MINING_REWARD, nil},
+		{"after halving", halving, vars.MINING_REWARD / 2, nil},
+		{"old subsidy after halving", halving, vars.MINING_REWARD, ErrBadCoinBaseAmount},
+	}
+	for _, d := range data {
+		coinBase := NewCoinBaseTX(address, d.height, 0)
+		coinBase.VOut[0].Value = d.subsidy
+		coinBase.Hash = coinBase.CalcHash()
+		block := newTestBlock([]types.Transaction{coinBase})
+		block.Height = d.height
+		view := newUTXOView(fetch, d.height)
+		actual := checkBlockTransactions(block, view, lockContext{height: d.height, medianTime: 1536000000})
+		if actual != d.expected {
+			test.Errorf("core.TestCheckBlockTransactions_Subsidy, %s:\nactual:\n%v\nexpected:\n%v", d.name, actual, d.expected)
+		}
+	}
+	if subsidy := GetBlockSubsidy(halving); subsidy != vars.MINING_REWARD/2 {
+		test.Errorf("core.TestCheckBlockTransactions_Subsidy, subsidy:\nactual:\n%s\nexpected:\n%s", subsidy, money.Amount(vars.MINING_REWARD/2))
+	}
+}
+
+func TestCheckBlockTransactions_CoinBaseHeight(test *testing.T) {
+	address := string(wallet.NewWallet().GetAddress())
+	fetch := func(txHash []byte, outIdx int) (Coin, bool) {
+		return Coin{}, false
+	}
+	withExtraNonce := NewCoinBaseTX(address, 300, 0)
+	withExtraNonce.VIn[0].ScriptSig = append(withExtraNonce.VIn[0].ScriptSig, []byte("extra nonce")...)
+	withExtraNonce.Hash = withExtraNonce.CalcHash()
+	withoutHeight := NewCoinBaseTX(address, 300, 0)
+	withoutHeight.VIn[0].ScriptSig = []byte("extra nonce")
+	withoutHeight.Hash = withoutHeight.CalcHash()
+	data := []struct {
+		name     string
+		coinBase types.Transaction
+		expected error
+	}{
+		{"height", NewCoinBaseTX(address, 300, 0), nil},
+		{"height with extra nonce", withExtraNonce, nil},
+		{"another height", NewCoinBaseTX(address, 299, 0), ErrBadCoinBaseHeight},
+		{"no height", withoutHeight, ErrBadCoinBaseHeight},
+	}
+	for _, d := range data {
+		block := newTestBlock([]types.Transaction{d.coinBase})
+		block.Height = 300
+		actual := checkBlockTransactions(block, newUTXOView(fetch, 300), lockContext{height: 300, medianTime: 1536000000})
+		if actual != d.expected {
+			test.Errorf("core.TestCheckBlockTransactions_CoinBaseHeight, %s:\nactual:\n%v\nexpected:\n%v", d.name, actual, d.expected)
+		}
+	}
+}
+
+func TestCheckBlockTransactions_Overwrite(test *testing.T) {
+	address := string(wallet.NewWallet().GetAddress())
+	coinBase := NewCoinBaseTX(address, 300, 0)
+	data := []struct {
+		name     string
+		unspent  bool
+		expected error
+	}{
+		{"unspent outputs", true, ErrOverwriteTx},
+		{"spent outputs", false, nil},
+	}
+	for _, d := range data {
+		unspent := d.unspent
+		fetch := func(txHash []byte, outIdx int) (Coin, bool) {
+			if unspent && bytes.Equal(txHash, coinBase.Hash) && outIdx == 0 {
+				return Coin{Output: coinBase.VOut[0], Height: 1, CoinBase: true}, true
+			}
+			return Coin{}, false
+		}
+		block := newTestBlock([]types.Transaction{coinBase})
+		block.Height = 300
+		actual := checkBlockTransactions(block, newUTXOView(fetch, 300), lockContext{height: 300, medianTime: 1536000000})
+		if actual != d.expected {
+			test.Errorf("core.TestCheckBlockTransactions_Overwrite, %s:\nactual:\n%v\nexpected:\n%v", d.name, actual, d.expected)
+		}
+	}
+}
+
+func TestCheckBlockTransactions_SigOps(test *testing.T) {
+	address := string(wallet.NewWallet().GetAddress())
+	coinBase := NewCoinBaseTX(address, 0, 0)
+	coinBase.VOut[0].ScriptPubKey = bytes.Repeat([]byte{script.OP_CHECKSIG}, vars.MAX_BLOCK_SIGOPS)
+	coinBase.Hash = coinBase.CalcHash()
+	fetch := func(txHash []byte, outIdx int) (Coin, bool) {
+		return Coin{}, false
+	}
+
+	// The limit is reached by the coin base, so any signature check is excessive.
+	tx := newTestTx("tx", []tx_io.TXInput{{PreviousTx: []byte("prev"), VOut: 0}}, 1)
+	tx.VOut[0].ScriptPubKey = []byte{script.OP_CHECKSIG}
+	block := newTestBlock([]types.Transaction{coinBase, tx})
+	actual := checkBlockTransactions(block, newUTXOView(fetch, 0), lockContext{medianTime: 1536000000})
+	if actual != ErrTooManySigOps {
+		test.Errorf("core.TestCheckBlockTransactions_SigOps:\nactual:\n%v\nexpected:\n%v", actual, ErrTooManySigOps)
+	}
+}
+
+func TestCheckBlockTime(test *testing.T) {
+	const medianTime, adjustedTime = 1536000000, 1536000600
+	data := []struct {
+		name      string
+		timestamp int64
+		expected  error
+	}{
+		{"equal to median time past", medianTime, ErrTimeTooOld},
+		{"above median time past", medianTime + 1, nil},
+		{"adjusted time", adjustedTime, nil},
+		{"max drift", adjustedTime + vars.MAX_FUTURE_BLOCK_TIME, nil},
+		{"too far in the future", adjustedTime + vars.MAX_FUTURE_BLOCK_TIME + 1, ErrTimeTooNew},
+	}
+	for _, d := range data {
+		actual := checkBlockTime(types.BlockHeader{Timestamp: d.timestamp}, medianTime, adjustedTime)
+		if actual != d.expected {
+			test.Errorf("core.TestCheckBlockTime, %s:\nactual:\n%v\nexpected:\n%v", d.name, actual, d.expected)
+		}
+	}
+}
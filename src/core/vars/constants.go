@@ -18,9 +18,107 @@ package vars
 import "math"
 
 const (
-	TARGET_BITS       = 16
-	MINING_REWARD     = 50.0
-	MIN_CURRENCY_UNIT = 0.000001
+	BLOCK_VERSION     = 1
+	TX_VERSION        = 2
+	MIN_CURRENCY_UNIT = 1
+	COIN              = 1000000 * MIN_CURRENCY_UNIT
+	MAX_MONEY         = 21000000 * COIN
+	MINING_REWARD     = 50 * COIN
 	MIN_FEE_PER_BYTE  = 20 * MIN_CURRENCY_UNIT
-	MAX_NONCE         = math.MaxInt32
+	MAX_NONCE         = math.MaxUint32
+)
+
+// DB_VERSION is the version of the storage format. It changes with every
+// change of how blocks or the chain state are stored:
+//
+//	1 - blocks in the wire format, inputs hold a signature and a public key,
+//	    outputs hold a public key hash;
+//	2 - inputs and outputs hold scripts, inputs have sequences, transactions
+//	    have lock times and unspent outputs hold heights;
+//	3 - unspent outputs hold coin base flags.
+//
+// Scripts replaced signatures and keys before the version was raised to 2,
+// so a database of version 1 may hold inputs of either layout.
+const DB_VERSION = 3
+
+// Block limits.
+const (
+
+	// MAX_BLOCK_SIZE is the maximum size of a serialized block in bytes.
+	MAX_BLOCK_SIZE = 1000000
+
+	// MAX_BLOCK_SIGOPS is the maximum number of signature checks in a block.
+	MAX_BLOCK_SIGOPS = MAX_BLOCK_SIZE / 50
+)
+
+// SUBSIDY_HALVING_INTERVAL is the number of blocks after which the block
+// subsidy, starting from MINING_REWARD, is halved.
+const SUBSIDY_HALVING_INTERVAL = 210000
+
+// COINBASE_MATURITY is the number of blocks which must be built on top of
+// a coin base transaction before its outputs can be spent.
+const COINBASE_MATURITY = 100
+
+// HASHRATE_REPORT_INTERVAL is the number of seconds between hash rate
+// reports of the miner.
+const HASHRATE_REPORT_INTERVAL = 10
+
+// Difficulty adjustment.
+const (
+
+	// POW_LIMIT_BITS is the compact form of the highest allowed target,
+	// i.e. the lowest difficulty.
+	POW_LIMIT_BITS = 0x1f00ffff
+
+	// TARGET_SPACING is the expected time between blocks in seconds.
+	TARGET_SPACING = 60
+
+	// DGW_PAST_BLOCKS is the number of previous blocks used for retargeting.
+	DGW_PAST_BLOCKS = 24
+)
+
+// Lock times.
+const (
+
+	// LOCKTIME_THRESHOLD separates lock times which are block heights
+	// from the ones which are unix timestamps.
+	LOCKTIME_THRESHOLD = 500000000
+
+	// MEDIAN_TIME_SPAN is the number of blocks used to calculate median
+	// time past, which time based locks are compared with.
+	MEDIAN_TIME_SPAN = 11
+
+	// SEQUENCE_FINAL disables lock time of the transaction, if all of its
+	// inputs have it, and relative lock time of the input.
+	SEQUENCE_FINAL = 0xffffffff
+
+	// SEQUENCE_LOCKTIME_DISABLE_FLAG disables relative lock time of the input.
+	SEQUENCE_LOCKTIME_DISABLE_FLAG = 1 << 31
+
+	// SEQUENCE_LOCKTIME_TYPE_FLAG makes relative lock time a number of
+	// SEQUENCE_LOCKTIME_GRANULARITY second units instead of blocks.
+	SEQUENCE_LOCKTIME_TYPE_FLAG = 1 << 22
+
+	// SEQUENCE_LOCKTIME_MASK extracts relative lock time from the sequence.
+	SEQUENCE_LOCKTIME_MASK = 0x0000ffff
+
+	SEQUENCE_LOCKTIME_GRANULARITY = 9
+)
+
+// Block time.
+const (
+
+	// MAX_FUTURE_BLOCK_TIME is the default number of seconds a block's
+	// timestamp may be ahead of network-adjusted time.
+	MAX_FUTURE_BLOCK_TIME = 2 * 60 * 60
+
+	// MAX_TIME_ADJUSTMENT limits the offset applied to local time
+	// to get network-adjusted time.
+	MAX_TIME_ADJUSTMENT = 70 * 60
+
+	// MIN_TIME_SAMPLES is the number of peers required to adjust local time.
+	MIN_TIME_SAMPLES = 5
+
+	// MAX_TIME_SAMPLES limits the number of peers whose time is tracked.
+	MAX_TIME_SAMPLES = 200
 )
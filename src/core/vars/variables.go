@@ -21,4 +21,5 @@ var (
 	Syncing      int32
 	DBMutex     = &sync.Mutex{}
 	UTXO_BUCKET = []byte("chainstate")
+	UNDO_BUCKET = []byte("undo")
 )
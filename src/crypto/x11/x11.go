@@ -16,6 +16,8 @@
 package x11
 
 import (
+	"sync"
+
 	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/sha3/blake512"
 	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/sha3/bmw512"
 	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/sha3/cubehash512"
@@ -27,109 +29,89 @@ import (
 	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/sha3/shavite512"
 	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/sha3/simd512"
 	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/sha3/skein512"
+	"github.com/YuriyLisovskiy/blockchain-go/src/crypto/sha3/utils"
 )
 
-var (
+// Hasher computes X11 hashes. It owns its digests and scratch buffers,
+// so it can be reused for many hashes, but not by several goroutines at once.
+type Hasher struct {
 	tha [64]byte
 	thb [64]byte
 
-	blakeHash = blake512.New()
-	bmwHash = bmw512.New()
-	groestlHash = groestl512.New()
-	skeinHash = skein512.New()
-	jhHash = jh512.New()
-	keccakHash = keccak512.New()
-	luffaHash = luffa512.New()
-	cubehashHash = cubehash512.New()
-	shaviteHash = shavite512.New()
-	simdHash = simd512.New()
-	echoHash = echo512.New()
-)
-
-// Hash computes the hash from the src bytes and returns 32-byte hash.
-func Sum256(src []byte) [32]byte {
-	ta := tha[:]
-	tb := thb[:]
-
-	blakeHash.Write(src)
-	blakeHash.Close(tb, 0, 0)
-
-	bmwHash.Write(tb)
-	bmwHash.Close(ta, 0, 0)
-
-	groestlHash.Write(ta)
-	groestlHash.Close(tb, 0, 0)
-
-	skeinHash.Write(tb)
-	skeinHash.Close(ta, 0, 0)
-
-	jhHash.Write(ta)
-	jhHash.Close(tb, 0, 0)
-
-	keccakHash.Write(tb)
-	keccakHash.Close(ta, 0, 0)
-
-	luffaHash.Write(ta)
-	luffaHash.Close(tb, 0, 0)
-
-	cubehashHash.Write(tb)
-	cubehashHash.Close(ta, 0, 0)
-
-	shaviteHash.Write(ta)
-	shaviteHash.Close(tb, 0, 0)
+	chain [11]utils.Digest
+}
 
-	simdHash.Write(tb)
-	simdHash.Close(ta, 0, 0)
+// New creates a Hasher with the chain of eleven digests in X11 order.
+func New() *Hasher {
+	return &Hasher{
+		chain: [11]utils.Digest{
+			blake512.New(),
+			bmw512.New(),
+			groestl512.New(),
+			skein512.New(),
+			jh512.New(),
+			keccak512.New(),
+			luffa512.New(),
+			cubehash512.New(),
+			shavite512.New(),
+			simd512.New(),
+			echo512.New(),
+		},
+	}
+}
 
-	echoHash.Write(ta)
-	echoHash.Close(tb, 0, 0)
+// hash passes src through the digest chain and returns the buffer
+// holding the last digest's output.
+func (h *Hasher) hash(src []byte) []byte {
+	in, out := h.tha[:], h.thb[:]
+	for i, digest := range h.chain {
+		if i == 0 {
+			digest.Write(src)
+		} else {
+			digest.Write(in)
+		}
+
+		// Close resets the digest, so it is ready for the next hash.
+		digest.Close(out, 0, 0)
+		in, out = out, in
+	}
+	return in
+}
 
+// Sum256 computes the hash from the src bytes and returns 32-byte hash.
+func (h *Hasher) Sum256(src []byte) [32]byte {
 	var res [32]byte
-	copy(res[:], tb)
-
+	copy(res[:], h.hash(src))
 	return res
 }
 
-// Hash computes the hash from the src bytes and returns 64-byte hash.
-func Sum512(src []byte) [64]byte {
-	ta := tha[:]
-	tb := thb[:]
-
-	blakeHash.Write(src)
-	blakeHash.Close(tb, 0, 0)
-
-	bmwHash.Write(tb)
-	bmwHash.Close(ta, 0, 0)
-
-	groestlHash.Write(ta)
-	groestlHash.Close(tb, 0, 0)
-
-	skeinHash.Write(tb)
-	skeinHash.Close(ta, 0, 0)
-
-	jhHash.Write(ta)
-	jhHash.Close(tb, 0, 0)
-
-	keccakHash.Write(tb)
-	keccakHash.Close(ta, 0, 0)
-
-	luffaHash.Write(ta)
-	luffaHash.Close(tb, 0, 0)
-
-	cubehashHash.Write(tb)
-	cubehashHash.Close(ta, 0, 0)
-
-	shaviteHash.Write(ta)
-	shaviteHash.Close(tb, 0, 0)
-
-	simdHash.Write(tb)
-	simdHash.Close(ta, 0, 0)
+// Sum512 computes the hash from the src bytes and returns 64-byte hash.
+func (h *Hasher) Sum512(src []byte) [64]byte {
+	var res [64]byte
+	copy(res[:], h.hash(src))
+	return res
+}
 
-	echoHash.Write(ta)
-	echoHash.Close(tb, 0, 0)
+// hasherPool holds hashers used by package-level functions, so they
+// can be called concurrently without allocating a digest chain per call.
+var hasherPool = sync.Pool{
+	New: func() interface{} {
+		return New()
+	},
+}
 
-	var res [64]byte
-	copy(res[:], tb)
+// Sum256 computes the hash from the src bytes and returns 32-byte hash.
+// It is safe for concurrent use.
+func Sum256(src []byte) [32]byte {
+	h := hasherPool.Get().(*Hasher)
+	defer hasherPool.Put(h)
+	return h.Sum256(src)
+}
 
-	return res
+// Sum512 computes the hash from the src bytes and returns 64-byte hash.
+// It is safe for concurrent use.
+func Sum512(src []byte) [64]byte {
+	h := hasherPool.Get().(*Hasher)
+	defer hasherPool.Put(h)
+	return h.Sum512(src)
 }
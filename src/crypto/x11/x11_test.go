@@ -36,6 +36,7 @@ package x11
 import (
 	"bytes"
 	"encoding/hex"
+	"sync"
 	"testing"
 )
 
@@ -121,3 +122,34 @@ var Sum256_Data = []struct {
 		[]byte("534536a4e4f16b32447f02f77200449dc2f23b532e3d9878fe111c9de666bc5c"),
 	},
 }
+
+func TestHasher_Reuse(t *testing.T) {
+	h := New()
+	for round := 0; round < 2; round++ {
+		for i := range Sum256_Data {
+			out := h.Sum256(Sum256_Data[i].in)
+			if hex.EncodeToString(out[:]) != string(Sum256_Data[i].out) {
+				t.Errorf("%s: invalid hash in round %d", Sum256_Data[i].id, round)
+			}
+		}
+	}
+}
+
+func TestSum256_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 50; n++ {
+				d := Sum256_Data[n%len(Sum256_Data)]
+				out := Sum256(d.in)
+				if hex.EncodeToString(out[:]) != string(d.out) {
+					t.Errorf("%s: invalid hash", d.id)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
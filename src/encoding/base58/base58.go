@@ -33,7 +33,9 @@ func Encode(input []byte) []byte {
 		result = append(result, b58Alphabet[mod.Int64()])
 	}
 	reverseBytes(result)
-	for b := range input {
+
+	// Every leading zero byte is encoded as the first character of the alphabet.
+	for _, b := range input {
 		if b == 0x00 {
 			result = append([]byte{b58Alphabet[0]}, result...)
 		} else {
@@ -46,10 +48,11 @@ func Encode(input []byte) []byte {
 func Decode(input []byte) []byte {
 	result := big.NewInt(0)
 	zeroBytes := 0
-	for b := range input {
-		if b == 0x00 {
-			zeroBytes++
+	for _, b := range input {
+		if b != b58Alphabet[0] {
+			break
 		}
+		zeroBytes++
 	}
 	payload := input[zeroBytes:]
 	for _, b := range payload {
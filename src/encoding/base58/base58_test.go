@@ -15,7 +15,10 @@
 
 package base58
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+)
 
 var Base58Decode_Data = []struct {
 	input    []byte
@@ -95,55 +98,55 @@ var Base58Encode_Data = []struct {
 }{
 	{
 		input:    []byte("Malesuada nisl ultricies curae;. Lectus habitant dis platea. At etiam."),
-		expected: []byte("14QDwy7VLCMuvcaSAXcKrqAd3XtVzQWdfiVh14RGLbYepbFQpc8TeFy8Ctrex6tB8Kctj94KrTTY84tpzwRFeujdH7mYJV4qK"),
+		expected: []byte("4QDwy7VLCMuvcaSAXcKrqAd3XtVzQWdfiVh14RGLbYepbFQpc8TeFy8Ctrex6tB8Kctj94KrTTY84tpzwRFeujdH7mYJV4qK"),
 	},
 	{
 		input:    []byte("Neque massa vel vivamus Tellus varius. Class diam fusce interdum."),
-		expected: []byte("17vFeFEjfUUx1PU73y3pucVqAyEMnzLtsXjuCSWxqNd2os6sDWbY8GavEucb2v53D3GUhhHXvKgykcKySDPeY2fR1o"),
+		expected: []byte("7vFeFEjfUUx1PU73y3pucVqAyEMnzLtsXjuCSWxqNd2os6sDWbY8GavEucb2v53D3GUhhHXvKgykcKySDPeY2fR1o"),
 	},
 	{
 		input:    []byte("Malesuada potenti aliquet Sollicitudin ridiculus. Velit bibendum montes urna tempor."),
-		expected: []byte("16X2XbHhikXCytvGxC5EFDz3so3Uhnr6LYFRroCCZdTn18xu8hcmXJ2ALPqAmoQmHnMaC7919EgBhwD2PK2aaLSyHg9Qw8FMtCZuxpariZDsq7WnyntV"),
+		expected: []byte("6X2XbHhikXCytvGxC5EFDz3so3Uhnr6LYFRroCCZdTn18xu8hcmXJ2ALPqAmoQmHnMaC7919EgBhwD2PK2aaLSyHg9Qw8FMtCZuxpariZDsq7WnyntV"),
 	},
 	{
 		input:    []byte("Inceptos, nam lectus ultrices auctor quisque in tortor. Conubia porta."),
-		expected: []byte("14EA8HigkVXU4FGjuTgivtskr5mmG12rtDqZf4cZ5dkd3No5FvQPeUjzdY7sVdgeF2iZCG5Lg1dtA51tdMqMS8XWobLEDdFky"),
+		expected: []byte("4EA8HigkVXU4FGjuTgivtskr5mmG12rtDqZf4cZ5dkd3No5FvQPeUjzdY7sVdgeF2iZCG5Lg1dtA51tdMqMS8XWobLEDdFky"),
 	},
 	{
 		input:    []byte("In. Varius quam nostra lacinia sed nisi nisl montes sociosqu."),
-		expected: []byte("1zSF5ery8S7KV4gSraVeEKHXneb7DZZF8TUn9VQf5oe8ezgqMLiN3ecR75FokGtpKdRsB6LA8V2G4CpeUzP7"),
+		expected: []byte("zSF5ery8S7KV4gSraVeEKHXneb7DZZF8TUn9VQf5oe8ezgqMLiN3ecR75FokGtpKdRsB6LA8V2G4CpeUzP7"),
 	},
 	{
 		input:    []byte("Dui placerat enim id convallis senectus. Phasellus in pharetra nam."),
-		expected: []byte("132h8WYAJtCqi6rSj3pbJ1YmV2o1kRTon6BzQA7as1iJLC6gMJUxTDdotoTjUTvF9dvsGze8GVKB1UYeihzQAM7Ck2BRX"),
+		expected: []byte("32h8WYAJtCqi6rSj3pbJ1YmV2o1kRTon6BzQA7as1iJLC6gMJUxTDdotoTjUTvF9dvsGze8GVKB1UYeihzQAM7Ck2BRX"),
 	},
 	{
 		input:    []byte("Eleifend venenatis molestie vel massa quisque sociosqu cum condimentum platea."),
-		expected: []byte("13FdZRynTwFbNueGLxkRZKrsFb7H72p2QuP1L1tXrQKWVwBUcmeyvySq4nUFycjSbeGpCjRJ5zvrTRYSjdA4PcEaxdgcuNMG3KkdC3ogWb8R"),
+		expected: []byte("3FdZRynTwFbNueGLxkRZKrsFb7H72p2QuP1L1tXrQKWVwBUcmeyvySq4nUFycjSbeGpCjRJ5zvrTRYSjdA4PcEaxdgcuNMG3KkdC3ogWb8R"),
 	},
 	{
 		input:    []byte("Justo lectus lectus faucibus laoreet pellentesque placerat lacus dolor convallis."),
-		expected: []byte("14aiJhcFoVZs1bvVFPtysL4s1ep79vqFrGw67no6f8k7zAKGzpRiKNDF8UzehS95EFgBPkuDUG5WwoNgDL7W4ZEcCTqfLn1VTcdqBMVHdCLqTfEu"),
+		expected: []byte("4aiJhcFoVZs1bvVFPtysL4s1ep79vqFrGw67no6f8k7zAKGzpRiKNDF8UzehS95EFgBPkuDUG5WwoNgDL7W4ZEcCTqfLn1VTcdqBMVHdCLqTfEu"),
 	},
 	{
 		input:    []byte("Purus metus ut. Condimentum. Suscipit, fermentum integer vivamus porta turpis."),
-		expected: []byte("13cPufgKmy5NUYKGFoYzrHRpi3WJmF4qz5Xay1napuxwLAK3A1unfDyzfbruSthKEprdxTMwgrcYPcU7ZLsaXxFqBvoD6uJBJryypYUMMhmP"),
+		expected: []byte("3cPufgKmy5NUYKGFoYzrHRpi3WJmF4qz5Xay1napuxwLAK3A1unfDyzfbruSthKEprdxTMwgrcYPcU7ZLsaXxFqBvoD6uJBJryypYUMMhmP"),
 	},
 	{
 		input:    []byte("A aliquet condimentum accumsan senectus integer. Luctus cursus elit integer."),
-		expected: []byte("17Hqb1ZA3mRWydXgtGDK6vGQ6ydLTjcu4to5oUCvW2Pqrzd51BEauyeASX3dThHQesBBKkr1mzkJwivPA2Rypqo6DuqkpT8jGUvXNoSS1"),
+		expected: []byte("7Hqb1ZA3mRWydXgtGDK6vGQ6ydLTjcu4to5oUCvW2Pqrzd51BEauyeASX3dThHQesBBKkr1mzkJwivPA2Rypqo6DuqkpT8jGUvXNoSS1"),
 	},
 	{
 		input:    []byte("Arcu, suspendisse, tincidunt suspendisse faucibus diam commodo dictumst, torquent. Erat."),
-		expected: []byte("1XYBMqXpnYZa9ATfe1G1vaY8mcN9kx287hwHg1Gf5t1ZXqcRFsmWGNh4VS5hYuEZpQxgao7kJxhztJvj6Y6w4adigJo9oBeiJ7SW51KAhSYVMYh91Z55HvcRB"),
+		expected: []byte("XYBMqXpnYZa9ATfe1G1vaY8mcN9kx287hwHg1Gf5t1ZXqcRFsmWGNh4VS5hYuEZpQxgao7kJxhztJvj6Y6w4adigJo9oBeiJ7SW51KAhSYVMYh91Z55HvcRB"),
 	},
 	{
 		input:    []byte("Dui cubilia facilisi netus integer facilisi suscipit et condimentum At."),
-		expected: []byte("1EH8nWtjCrkYSDs7jXHpkJ2DFMxKhuxZWLk7sFqYx7o1V3Q6xp92wKGcFx5nCvbEoLdWhRssLqAPhzG2bkRnRkfZPpanLNMsvM"),
+		expected: []byte("EH8nWtjCrkYSDs7jXHpkJ2DFMxKhuxZWLk7sFqYx7o1V3Q6xp92wKGcFx5nCvbEoLdWhRssLqAPhzG2bkRnRkfZPpanLNMsvM"),
 	},
 	{
 		input:    []byte("Rhoncus quam eros felis Pharetra porttitor nibh sem tincidunt aliquam."),
-		expected: []byte("14d38gbgsEi2sRdEdX5cuVKRKqbvzdKfEnrkMXroKvwx47CRtcuMfgWa1ZZ3cYr62LqoBuG3te4XiqhXCDqtQoWPHLWKnBxYD"),
+		expected: []byte("4d38gbgsEi2sRdEdX5cuVKRKqbvzdKfEnrkMXroKvwx47CRtcuMfgWa1ZZ3cYr62LqoBuG3te4XiqhXCDqtQoWPHLWKnBxYD"),
 	},
 }
 
@@ -201,3 +204,23 @@ func TestReverseBytes(test *testing.T) {
 		}
 	}
 }
+
+func TestBase58_LeadingZeros(test *testing.T) {
+	data := []struct {
+		decoded []byte
+		encoded []byte
+	}{
+		{[]byte{}, []byte("")},
+		{[]byte{0x6f, 0x01}, []byte("9Sx")},
+		{[]byte{0x00, 0x00, 0x01}, []byte("112")},
+		{[]byte{0x00, 0x00}, []byte("11")},
+	}
+	for _, d := range data {
+		if actual := Encode(d.decoded); !bytes.Equal(actual, d.encoded) {
+			test.Errorf("base58.TestBase58_LeadingZeros, %x:\nactual:\n%s\nexpected:\n%s", d.decoded, actual, d.encoded)
+		}
+		if actual := Decode(d.encoded); !bytes.Equal(actual, d.decoded) {
+			test.Errorf("base58.TestBase58_LeadingZeros, %s:\nactual:\n%x\nexpected:\n%x", d.encoded, actual, d.decoded)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package wire implements primitives of the canonical binary format used
+// for hashing and storing blocks and transactions. Integers are written in
+// little-endian order, lengths and counts are written as variable length
+// integers in the same way as Bitcoin does.
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// MAX_PAYLOAD_SIZE limits the length of variable length fields and counts,
+// so malformed data can not cause huge allocations.
+const MAX_PAYLOAD_SIZE = 32 * 1024 * 1024
+
+var (
+	ErrNonCanonicalVarInt = errors.New("non-canonical varint")
+	ErrTooLarge           = errors.New("length exceeds maximum payload size")
+	ErrTrailingData       = errors.New("unexpected data after the end of an object")
+)
+
+// WriteUint32 writes v in little-endian order.
+func WriteUint32(w io.Writer, v uint32) error {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// ReadUint32 reads a little-endian uint32.
+func ReadUint32(r io.Reader) (uint32, error) {
+	var buf [4]byte
+	_, err := io.ReadFull(r, buf[:])
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf[:]), nil
+}
+
+// WriteUint64 writes v in little-endian order.
+func WriteUint64(w io.Writer, v uint64) error {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// ReadUint64 reads a little-endian uint64.
+func ReadUint64(r io.Reader) (uint64, error) {
+	var buf [8]byte
+	_, err := io.ReadFull(r, buf[:])
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+// VarIntSize returns the number of bytes WriteVarInt uses to encode n.
+func VarIntSize(n uint64) int {
+	switch {
+	case n < 0xfd:
+		return 1
+	case n <= 0xffff:
+		return 3
+	case n <= 0xffffffff:
+		return 5
+	default:
+		return 9
+	}
+}
+
+// WriteVarInt writes n using the smallest possible variable length encoding:
+// values below 0xfd take one byte, larger ones are prefixed with 0xfd, 0xfe
+// or 0xff followed by 2, 4 or 8 bytes respectively.
+func WriteVarInt(w io.Writer, n uint64) error {
+	var buf [9]byte
+	var size int
+	switch VarIntSize(n) {
+	case 1:
+		buf[0] = uint8(n)
+		size = 1
+	case 3:
+		buf[0] = 0xfd
+		binary.LittleEndian.PutUint16(buf[1:], uint16(n))
+		size = 3
+	case 5:
+		buf[0] = 0xfe
+		binary.LittleEndian.PutUint32(buf[1:], uint32(n))
+		size = 5
+	default:
+		buf[0] = 0xff
+		binary.LittleEndian.PutUint64(buf[1:], n)
+		size = 9
+	}
+	_, err := w.Write(buf[:size])
+	return err
+}
+
+// ReadVarInt reads a variable length integer. Encodings which are longer
+// than necessary are rejected, so each value has exactly one encoding.
+func ReadVarInt(r io.Reader) (uint64, error) {
+	var buf [8]byte
+	_, err := io.ReadFull(r, buf[:1])
+	if err != nil {
+		return 0, err
+	}
+	var n, min uint64
+	switch buf[0] {
+	case 0xfd:
+		_, err = io.ReadFull(r, buf[:2])
+		n, min = uint64(binary.LittleEndian.Uint16(buf[:2])), 0xfd
+	case 0xfe:
+		_, err = io.ReadFull(r, buf[:4])
+		n, min = uint64(binary.LittleEndian.Uint32(buf[:4])), 0x10000
+	case 0xff:
+		_, err = io.ReadFull(r, buf[:8])
+		n, min = binary.LittleEndian.Uint64(buf[:8]), 0x100000000
+	default:
+		return uint64(buf[0]), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if n < min {
+		return 0, ErrNonCanonicalVarInt
+	}
+	return n, nil
+}
+
+// ReadCount reads a variable length count of elements and checks it
+// against MAX_PAYLOAD_SIZE.
+func ReadCount(r io.Reader) (int, error) {
+	n, err := ReadVarInt(r)
+	if err != nil {
+		return 0, err
+	}
+	if n > MAX_PAYLOAD_SIZE {
+		return 0, ErrTooLarge
+	}
+	return int(n), nil
+}
+
+// WriteVarBytes writes the length of b as a varint followed by b itself.
+func WriteVarBytes(w io.Writer, b []byte) error {
+	err := WriteVarInt(w, uint64(len(b)))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// ReadVarBytes reads a byte slice written by WriteVarBytes.
+func ReadVarBytes(r io.Reader) ([]byte, error) {
+	n, err := ReadCount(r)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
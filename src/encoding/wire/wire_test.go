@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package wire
+
+import (
+	"bytes"
+	"testing"
+)
+
+var VarInt_Data = []struct {
+	value   uint64
+	encoded []byte
+}{
+	{value: 0, encoded: []byte{0x00}},
+	{value: 0xfc, encoded: []byte{0xfc}},
+	{value: 0xfd, encoded: []byte{0xfd, 0xfd, 0x00}},
+	{value: 0xffff, encoded: []byte{0xfd, 0xff, 0xff}},
+	{value: 0x10000, encoded: []byte{0xfe, 0x00, 0x00, 0x01, 0x00}},
+	{value: 0xffffffff, encoded: []byte{0xfe, 0xff, 0xff, 0xff, 0xff}},
+	{value: 0x100000000, encoded: []byte{0xff, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}},
+}
+
+func TestVarInt(test *testing.T) {
+	for i, data := range VarInt_Data {
+		var buf bytes.Buffer
+		err := WriteVarInt(&buf, data.value)
+		if err != nil || !bytes.Equal(buf.Bytes(), data.encoded) {
+			test.Errorf("wire.TestVarInt[%d]:\nactual:\n%x\nexpected:\n%x", i, buf.Bytes(), data.encoded)
+		}
+		if VarIntSize(data.value) != len(data.encoded) {
+			test.Errorf("wire.TestVarInt[%d]: size:\nactual:\n%d\nexpected:\n%d", i, VarIntSize(data.value), len(data.encoded))
+		}
+		actual, err := ReadVarInt(bytes.NewReader(data.encoded))
+		if err != nil || actual != data.value {
+			test.Errorf("wire.TestVarInt[%d]:\nactual:\n%d, %v\nexpected:\n%d", i, actual, err, data.value)
+		}
+	}
+}
+
+var NonCanonicalVarInt_Data = [][]byte{
+	{0xfd, 0xfc, 0x00},
+	{0xfe, 0xff, 0xff, 0x00, 0x00},
+	{0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00},
+}
+
+func TestReadVarInt_NonCanonical(test *testing.T) {
+	for i, data := range NonCanonicalVarInt_Data {
+		_, err := ReadVarInt(bytes.NewReader(data))
+		if err != ErrNonCanonicalVarInt {
+			test.Errorf("wire.TestReadVarInt_NonCanonical[%d]:\nactual:\n%v\nexpected:\n%v", i, err, ErrNonCanonicalVarInt)
+		}
+	}
+}
+
+func TestVarBytes(test *testing.T) {
+	expected := []byte("some data")
+	var buf bytes.Buffer
+	err := WriteVarBytes(&buf, expected)
+	if err != nil {
+		test.Fatal(err)
+	}
+	actual, err := ReadVarBytes(&buf)
+	if err != nil || !bytes.Equal(actual, expected) {
+		test.Errorf("wire.TestVarBytes:\nactual:\n%x, %v\nexpected:\n%x", actual, err, expected)
+	}
+
+	// Length greater than the maximum payload size.
+	buf.Reset()
+	WriteVarInt(&buf, MAX_PAYLOAD_SIZE+1)
+	if _, err := ReadVarBytes(&buf); err != ErrTooLarge {
+		test.Errorf("wire.TestVarBytes:\nactual:\n%v\nexpected:\n%v", err, ErrTooLarge)
+	}
+}
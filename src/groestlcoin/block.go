@@ -0,0 +1,268 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package groestlcoin decodes blocks and transactions of Groestlcoin, which
+// use the wire format of Bitcoin, and checks proof of work of their headers.
+// It allows to analyze Groestlcoin chain data offline, blocks of this
+// format can not be added to the node's block chain.
+package groestlcoin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"log"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
+)
+
+var ErrBadWitnessFlag = errors.New("unknown transaction witness flag")
+
+// Block is a block in the wire format: an 80-byte header followed by
+// the number of transactions and the transactions themselves. The header
+// has the same layout as headers of the node's blocks.
+type Block struct {
+	Header       types.BlockHeader
+	Transactions []Tx
+}
+
+// Tx is a transaction in the wire format.
+type Tx struct {
+	Version  int32
+	TxIn     []TxIn
+	TxOut    []TxOut
+	LockTime uint32
+}
+
+// TxIn spends the output PrevIndex of the transaction PrevHash.
+// Witness is empty for transactions without segregated witness.
+type TxIn struct {
+	PrevHash  []byte
+	PrevIndex uint32
+	ScriptSig []byte
+	Sequence  uint32
+	Witness   [][]byte
+}
+
+// TxOut holds a value in units of 10^-8 coin and a script locking it.
+type TxOut struct {
+	Value    int64
+	PkScript []byte
+}
+
+// DecodeBlock reads a block in the wire format.
+func DecodeBlock(r io.Reader) (Block, error) {
+	var data [types.BLOCK_HEADER_SIZE]byte
+	_, err := io.ReadFull(r, data[:])
+	if err != nil {
+		return Block{}, err
+	}
+	header, err := types.DeserializeBlockHeader(data[:])
+	if err != nil {
+		return Block{}, err
+	}
+	count, err := wire.ReadCount(r)
+	if err != nil {
+		return Block{}, err
+	}
+	block := Block{Header: header}
+	for i := 0; i < count; i++ {
+		var tx Tx
+		err = tx.Decode(r)
+		if err != nil {
+			return Block{}, err
+		}
+		block.Transactions = append(block.Transactions, tx)
+	}
+	return block, nil
+}
+
+// Decode reads a transaction in the wire format. Transactions with
+// segregated witness have a zero marker instead of the number of inputs,
+// followed by a flag, and witnesses of all inputs after the outputs.
+func (tx *Tx) Decode(r io.Reader) error {
+	version, err := wire.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	tx.Version = int32(version)
+	count, err := wire.ReadCount(r)
+	if err != nil {
+		return err
+	}
+	hasWitness := false
+	if count == 0 {
+		var flag [1]byte
+		_, err = io.ReadFull(r, flag[:])
+		if err != nil {
+			return err
+		}
+		if flag[0] != 1 {
+			return ErrBadWitnessFlag
+		}
+		hasWitness = true
+		count, err = wire.ReadCount(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Counts are not trusted to preallocate slices, so a malformed
+	// transaction can not cause huge allocations before the data ends.
+	tx.TxIn = nil
+	for i := 0; i < count; i++ {
+		var in TxIn
+		err = in.decode(r)
+		if err != nil {
+			return err
+		}
+		tx.TxIn = append(tx.TxIn, in)
+	}
+	count, err = wire.ReadCount(r)
+	if err != nil {
+		return err
+	}
+	tx.TxOut = nil
+	for i := 0; i < count; i++ {
+		value, err := wire.ReadUint64(r)
+		if err != nil {
+			return err
+		}
+		pkScript, err := wire.ReadVarBytes(r)
+		if err != nil {
+			return err
+		}
+		tx.TxOut = append(tx.TxOut, TxOut{Value: int64(value), PkScript: pkScript})
+	}
+	if hasWitness {
+		for i := range tx.TxIn {
+			count, err = wire.ReadCount(r)
+			if err != nil {
+				return err
+			}
+			for j := 0; j < count; j++ {
+				item, err := wire.ReadVarBytes(r)
+				if err != nil {
+					return err
+				}
+				tx.TxIn[i].Witness = append(tx.TxIn[i].Witness, item)
+			}
+		}
+	}
+	tx.LockTime, err = wire.ReadUint32(r)
+	return err
+}
+
+func (in *TxIn) decode(r io.Reader) error {
+	in.PrevHash = make([]byte, types.HASH_SIZE)
+	_, err := io.ReadFull(r, in.PrevHash)
+	if err != nil {
+		return err
+	}
+	in.PrevIndex, err = wire.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	in.ScriptSig, err = wire.ReadVarBytes(r)
+	if err != nil {
+		return err
+	}
+	in.Sequence, err = wire.ReadUint32(r)
+	return err
+}
+
+// Encode writes the transaction in the wire format without witnesses,
+// which is the data its hash commits to.
+func (tx Tx) Encode(w io.Writer) error {
+	err := wire.WriteUint32(w, uint32(tx.Version))
+	if err != nil {
+		return err
+	}
+	err = wire.WriteVarInt(w, uint64(len(tx.TxIn)))
+	if err != nil {
+		return err
+	}
+	for _, in := range tx.TxIn {
+		_, err = w.Write(in.PrevHash)
+		if err != nil {
+			return err
+		}
+		err = wire.WriteUint32(w, in.PrevIndex)
+		if err != nil {
+			return err
+		}
+		err = wire.WriteVarBytes(w, in.ScriptSig)
+		if err != nil {
+			return err
+		}
+		err = wire.WriteUint32(w, in.Sequence)
+		if err != nil {
+			return err
+		}
+	}
+	err = wire.WriteVarInt(w, uint64(len(tx.TxOut)))
+	if err != nil {
+		return err
+	}
+	for _, out := range tx.TxOut {
+		err = wire.WriteUint64(w, uint64(out.Value))
+		if err != nil {
+			return err
+		}
+		err = wire.WriteVarBytes(w, out.PkScript)
+		if err != nil {
+			return err
+		}
+	}
+	return wire.WriteUint32(w, tx.LockTime)
+}
+
+// Hash computes the transaction's hash, which is a single SHA-256 of
+// the transaction without witnesses in Groestlcoin.
+func (tx Tx) Hash() []byte {
+	var buff bytes.Buffer
+	err := tx.Encode(&buff)
+	if err != nil {
+		log.Panic(err)
+	}
+	hash := sha256.Sum256(buff.Bytes())
+	return hash[:]
+}
+
+// IsCoinBase checks whether the transaction creates new coins.
+func (tx Tx) IsCoinBase() bool {
+	return len(tx.TxIn) == 1 && isZeroHash(tx.TxIn[0].PrevHash) && tx.TxIn[0].PrevIndex == 0xffffffff
+}
+
+// ReverseHash returns the hash in the reversed byte order, in which
+// Groestlcoin displays hashes of blocks and transactions.
+func ReverseHash(hash []byte) []byte {
+	reversed := make([]byte, len(hash))
+	for i, b := range hash {
+		reversed[len(hash)-1-i] = b
+	}
+	return reversed
+}
+
+func isZeroHash(hash []byte) bool {
+	for _, b := range hash {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
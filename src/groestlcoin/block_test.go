@@ -0,0 +1,103 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package groestlcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"runtime"
+	"testing"
+)
+
+func TestDecodeBlock_Genesis(test *testing.T) {
+	raw := mustDecodeHex(test, genesisBlockHex)
+	r := bytes.NewReader(raw)
+	block, err := DecodeBlock(r)
+	if err != nil || r.Len() != 0 {
+		test.Fatalf("groestlcoin.TestDecodeBlock_Genesis:\nactual:\n%v, %d bytes left\nexpected:\n<nil>, 0 bytes left", err, r.Len())
+	}
+	if len(block.Transactions) != 1 || !block.Transactions[0].IsCoinBase() {
+		test.Fatalf("groestlcoin.TestDecodeBlock_Genesis, transactions:\nactual:\n%v\nexpected:\na coin base", block.Transactions)
+	}
+	tx := block.Transactions[0]
+
+	// The only transaction's hash is the merkle root.
+	if !bytes.Equal(tx.Hash(), block.Header.MerkleRoot) {
+		test.Errorf("groestlcoin.TestDecodeBlock_Genesis, tx hash:\nactual:\n%x\nexpected:\n%x", tx.Hash(), block.Header.MerkleRoot)
+	}
+	if tx.TxOut[0].Value != 0 || !bytes.HasSuffix(tx.TxIn[0].ScriptSig, []byte("over Crimea")) {
+		test.Errorf("groestlcoin.TestDecodeBlock_Genesis, coin base:\nactual:\n%d %q", tx.TxOut[0].Value, tx.TxIn[0].ScriptSig)
+	}
+	var buff bytes.Buffer
+	err = tx.Encode(&buff)
+	if err != nil || !bytes.Equal(buff.Bytes(), raw[81:]) {
+		test.Errorf("groestlcoin.TestDecodeBlock_Genesis, encode:\nactual:\n%x\nexpected:\n%x", buff.Bytes(), raw[81:])
+	}
+}
+
+func TestTx_Decode_Witness(test *testing.T) {
+	prevHash := bytes.Repeat([]byte{0x11}, 32)
+	legacy := "02000000" + "01" + hex.EncodeToString(prevHash) + "01000000" + "00" + "feffffff" +
+		"01" + "e803000000000000" + "0151"
+	raw := mustDecodeHex(test, legacy[:8]+"0001"+legacy[8:]+"02"+"0201ff"+"00"+"00000000")
+	var tx Tx
+	err := tx.Decode(bytes.NewReader(raw))
+	if err != nil {
+		test.Fatal(err)
+	}
+	if len(tx.TxIn) != 1 || len(tx.TxIn[0].Witness) != 2 || !bytes.Equal(tx.TxIn[0].Witness[0], []byte{0x01, 0xff}) {
+		test.Errorf("groestlcoin.TestTx_Decode_Witness, witness:\nactual:\n%x\nexpected:\n[01ff ]", tx.TxIn[0].Witness)
+	}
+	if tx.Version != 2 || tx.TxIn[0].Sequence != 0xfffffffe || tx.TxOut[0].Value != 1000 {
+		test.Errorf("groestlcoin.TestTx_Decode_Witness, fields:\nactual:\n%+v", tx)
+	}
+
+	// Witnesses are not committed to by the transaction's hash.
+	var legacyTx Tx
+	err = legacyTx.Decode(bytes.NewReader(mustDecodeHex(test, legacy+"00000000")))
+	if err != nil || !bytes.Equal(tx.Hash(), legacyTx.Hash()) {
+		test.Errorf("groestlcoin.TestTx_Decode_Witness, hash:\nactual:\n%x, %v\nexpected:\n%x, <nil>", tx.Hash(), err, legacyTx.Hash())
+	}
+
+	raw[5] = 2
+	if err := new(Tx).Decode(bytes.NewReader(raw)); err != ErrBadWitnessFlag {
+		test.Errorf("groestlcoin.TestTx_Decode_Witness, flag:\nactual:\n%v\nexpected:\n%v", err, ErrBadWitnessFlag)
+	}
+}
+
+func TestTx_Decode_HugeCount(test *testing.T) {
+	data := []struct {
+		name string
+		raw  string
+	}{
+		{"inputs", "01000000" + "fe00000002"},
+		{"witness inputs", "01000000" + "0001" + "fe00000002"},
+		{"outputs", "01000000" + "01" + hex.EncodeToString(make([]byte, 32)) + "00000000" + "00" + "ffffffff" + "fe00000002"},
+	}
+	for _, d := range data {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		err := new(Tx).Decode(bytes.NewReader(mustDecodeHex(test, d.raw)))
+		runtime.ReadMemStats(&after)
+		if err != io.EOF {
+			test.Errorf("groestlcoin.TestTx_Decode_HugeCount, %s:\nactual:\n%v\nexpected:\n%v", d.name, err, io.EOF)
+		}
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+			test.Errorf("groestlcoin.TestTx_Decode_HugeCount, %s: %d bytes allocated", d.name, allocated)
+		}
+	}
+}
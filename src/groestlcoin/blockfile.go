@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package groestlcoin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
+)
+
+var ErrBadMagic = errors.New("block record does not start with the network magic")
+
+// BlockFileReader reads blocks from blk*.dat files of Groestlcoin Core.
+// Each block is stored as a record: the network magic and the size of
+// the block, both 4-byte little-endian, followed by the block itself.
+type BlockFileReader struct {
+	r   io.Reader
+	net uint32
+}
+
+// NewBlockFileReader creates a reader of records with given network magic.
+func NewBlockFileReader(r io.Reader, net uint32) *BlockFileReader {
+	return &BlockFileReader{r: r, net: net}
+}
+
+// Next reads the next block. It returns io.EOF at the end of the file,
+// which may be padded with zeros preallocated for further blocks.
+func (br *BlockFileReader) Next() (Block, error) {
+	magic, err := wire.ReadUint32(br.r)
+	if err == io.EOF || (err == nil && magic == 0) {
+		return Block{}, io.EOF
+	}
+	if err != nil {
+		return Block{}, err
+	}
+	if magic != br.net {
+		return Block{}, ErrBadMagic
+	}
+	size, err := wire.ReadUint32(br.r)
+	if err != nil {
+		return Block{}, err
+	}
+	if size > wire.MAX_PAYLOAD_SIZE {
+		return Block{}, wire.ErrTooLarge
+	}
+	data := make([]byte, size)
+	_, err = io.ReadFull(br.r, data)
+	if err != nil {
+		return Block{}, err
+	}
+	r := bytes.NewReader(data)
+	block, err := DecodeBlock(r)
+	if err == nil && r.Len() != 0 {
+		err = wire.ErrTrailingData
+	}
+	return block, err
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package groestlcoin
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/encoding/wire"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+func TestBlockFileReader(test *testing.T) {
+	net := params.GroestlcoinParams.Net
+	data := joinRecords(
+		newBlockRecord(net, newHeaderBlock(test, childHeadersHex[0])),
+		newBlockRecord(net, mustDecodeHex(test, genesisBlockHex)),
+		make([]byte, 16),
+	)
+	reader := NewBlockFileReader(bytes.NewReader(data), net)
+	var txCounts []int
+	for {
+		block, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			test.Fatal(err)
+		}
+		txCounts = append(txCounts, len(block.Transactions))
+	}
+	if len(txCounts) != 2 || txCounts[0] != 0 || txCounts[1] != 1 {
+		test.Errorf("groestlcoin.TestBlockFileReader:\nactual:\n%v\nexpected:\n[0 1]", txCounts)
+	}
+}
+
+func TestBlockFileReader_Errors(test *testing.T) {
+	net := params.GroestlcoinParams.Net
+	record := newBlockRecord(net, mustDecodeHex(test, genesisBlockHex))
+	trailing := newBlockRecord(net, append(mustDecodeHex(test, genesisBlockHex), 0))
+	data := []struct {
+		name     string
+		data     []byte
+		expected error
+	}{
+		{"another network", newBlockRecord(params.MainNetParams.Net, mustDecodeHex(test, genesisBlockHex)), ErrBadMagic},
+		{"truncated", record[:len(record)-1], io.ErrUnexpectedEOF},
+		{"trailing data", trailing, wire.ErrTrailingData},
+	}
+	for _, d := range data {
+		_, actual := NewBlockFileReader(bytes.NewReader(d.data), net).Next()
+		if actual != d.expected {
+			test.Errorf("groestlcoin.TestBlockFileReader_Errors, %s:\nactual:\n%v\nexpected:\n%v", d.name, actual, d.expected)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package groestlcoin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+// The genesis block of Groestlcoin, its hash is displayed as
+// 00000ac5927c594d49cc0bdb81759d0da8297eb614683d3acb62f0703b639023.
+const genesisBlockHex = "" +
+	"700000000000000000000000000000000000000000000000000000000000000000000000bb2866aaca46c4428ad08b57" +
+	"bc9d1493abaf64724b6c3052a7c8f958df68e93ced3d2b53ffff0f1e835b0300" + "01" +
+	"01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff3a04ffff001d01" +
+	"04325072657373757265206d75737420626520707574206f6e20566c6164696d697220507574696e206f766572204372" +
+	"696d6561ffffffff010000000000000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea" +
+	"1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+
+const genesisHashHex = "00000ac5927c594d49cc0bdb81759d0da8297eb614683d3acb62f0703b639023"
+
+// Headers mined on top of the genesis block at the network's proof of work
+// limit for these tests, they are not part of the Groestlcoin chain.
+var childHeadersHex = []string{
+	"700000002390633b70f062cb3a3d6814b67e29a80d9d7581db0bcc494d597c92c50a00002ae2f0decb81fc1ec7b1dcbc" +
+		"19912b19605e312b725268f01de9d6614d2d8926293e2b53ffff0f1e11fe0300",
+	"700000002ba36487e57f9e877f176942a20b89ad3a65bb0ebc2f07a158717ad9cf080000b743cbe1bb107033458694774a" +
+		"d7146dec3f339346803159e2c58f55e2ac67dc653e2b53ffff0f1e758f2c00",
+}
+
+var childHashesHex = []string{
+	"000008cfd97a7158a1072fbc0ebb653aad890ba24269177f879e7fe58764a32b",
+	"000002db87036247d109c8644e1fe4a63f4c070f2532a94b4d4f4e302c209a5d",
+}
+
+func mustDecodeHex(test *testing.T, s string) []byte {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		test.Fatal(err)
+	}
+	return data
+}
+
+// newBlockRecord stores the block the way blk*.dat files do.
+func newBlockRecord(net uint32, block []byte) []byte {
+	record := make([]byte, 8, 8+len(block))
+	binary.LittleEndian.PutUint32(record[0:4], net)
+	binary.LittleEndian.PutUint32(record[4:8], uint32(len(block)))
+	return append(record, block...)
+}
+
+// newHeaderBlock creates a block of the header without transactions.
+func newHeaderBlock(test *testing.T, headerHex string) []byte {
+	return append(mustDecodeHex(test, headerHex), 0)
+}
+
+func joinRecords(records ...[]byte) []byte {
+	return bytes.Join(records, nil)
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package groestlcoin
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/consensus"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+var (
+	ErrBadTarget    = errors.New("target of the header is out of range")
+	ErrHighHash     = errors.New("hash of the header does not meet the target")
+	ErrBadGenesis   = errors.New("genesis header does not belong to the network")
+	ErrNotConnected = errors.New("header does not connect to the previous one")
+)
+
+// HeaderHash computes the hash of the header with the proof of work
+// function of given network.
+func HeaderHash(header types.BlockHeader, p *params.ChainParams) []byte {
+	return p.PowHasher.Hash(header.Serialize())
+}
+
+// CheckHeaderPoW checks that the header's target is within the network's
+// limit and that its hash meets the target. Unlike the node's hashes,
+// hashes of Groestlcoin are compared as little-endian numbers.
+func CheckHeaderPoW(header types.BlockHeader, p *params.ChainParams) error {
+	target := consensus.CompactToBig(header.Bits)
+	if target.Sign() <= 0 || target.Cmp(consensus.CompactToBig(p.PowLimitBits)) > 0 {
+		return ErrBadTarget
+	}
+	hashInt := new(big.Int).SetBytes(ReverseHash(HeaderHash(header, p)))
+	if hashInt.Cmp(target) > 0 {
+		return ErrHighHash
+	}
+	return nil
+}
+
+// CheckHeaderChain checks proof of work of headers given in chain order
+// and that each of them refers to the previous one. The first header is
+// checked against the network's genesis hash if it has no parent. The
+// index of the first invalid header is returned with the error.
+func CheckHeaderChain(headers []types.BlockHeader, p *params.ChainParams) (int, error) {
+	var prevHash []byte
+	for i, header := range headers {
+		err := CheckHeaderPoW(header, p)
+		if err != nil {
+			return i, err
+		}
+		hash := HeaderHash(header, p)
+		if i == 0 {
+			if isZeroHash(header.PrevBlockHash) && !bytes.Equal(hash, p.GenesisHash) {
+				return i, ErrBadGenesis
+			}
+		} else if !bytes.Equal(header.PrevBlockHash, prevHash) {
+			return i, ErrNotConnected
+		}
+		prevHash = hash
+	}
+	return len(headers), nil
+}
+
+// BestHeaderChain orders headers read from block files, where blocks may
+// be stored out of order and stale blocks are kept as well. It returns
+// the chain with the most work starting at the genesis header or, if it
+// is missing, at the first header whose parent is unknown. Of chains with
+// equal work the one whose tip comes first in headers wins, like nodes
+// keep the block they received first. Headers which are not part of the
+// chain are counted as stale.
+func BestHeaderChain(headers []types.BlockHeader, p *params.ChainParams) ([]types.BlockHeader, int) {
+	if len(headers) == 0 {
+		return nil, 0
+	}
+	hashes := make([]string, len(headers))
+	byHash := make(map[string]int)
+	for i, header := range headers {
+		hashes[i] = string(HeaderHash(header, p))
+		byHash[hashes[i]] = i
+	}
+	children := make(map[string][]int)
+	root := -1
+	for i, header := range headers {
+		prev := string(header.PrevBlockHash)
+		if _, ok := byHash[prev]; ok {
+			children[prev] = append(children[prev], i)
+		} else if root == -1 {
+			root = i
+		}
+		if hashes[i] == string(p.GenesisHash) {
+			root = i
+		}
+	}
+	if root == -1 {
+
+		// Every header has a parent, which is possible only with cycles
+		// of hashes, so the data is not a chain.
+		return nil, len(headers)
+	}
+
+	// Walk the tree from the root breadth-first summing work of headers,
+	// the header with the most chain work is the tip.
+	parents := map[int]int{root: -1}
+	heights := map[int]int{root: 0}
+	work := map[int]*big.Int{root: consensus.CalcWork(consensus.CompactToBig(headers[root].Bits))}
+	tip := root
+	queue := []int{root}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		if cmp := work[i].Cmp(work[tip]); cmp > 0 || cmp == 0 && i < tip {
+			tip = i
+		}
+		for _, child := range children[hashes[i]] {
+			if _, seen := heights[child]; !seen {
+				parents[child] = i
+				heights[child] = heights[i] + 1
+				childWork := consensus.CalcWork(consensus.CompactToBig(headers[child].Bits))
+				work[child] = childWork.Add(childWork, work[i])
+				queue = append(queue, child)
+			}
+		}
+	}
+	chain := make([]types.BlockHeader, heights[tip]+1)
+	for i := tip; i != -1; i = parents[i] {
+		chain[heights[i]] = headers[i]
+	}
+	return chain, len(headers) - len(chain)
+}
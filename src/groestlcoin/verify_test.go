@@ -0,0 +1,135 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package groestlcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
+
+// newTestHeaders returns the genesis header followed by the child headers.
+func newTestHeaders(test *testing.T) []types.BlockHeader {
+	headers := []types.BlockHeader{}
+	for _, data := range append([]string{genesisBlockHex[:2*types.BLOCK_HEADER_SIZE]}, childHeadersHex...) {
+		header, err := types.DeserializeBlockHeader(mustDecodeHex(test, data))
+		if err != nil {
+			test.Fatal(err)
+		}
+		headers = append(headers, header)
+	}
+	return headers
+}
+
+func TestHeaderHash(test *testing.T) {
+	for i, header := range newTestHeaders(test) {
+		expected := append([]string{genesisHashHex}, childHashesHex...)[i]
+		actual := hex.EncodeToString(ReverseHash(HeaderHash(header, &params.GroestlcoinParams)))
+		if actual != expected {
+			test.Errorf("groestlcoin.TestHeaderHash[%d]:\nactual:\n%s\nexpected:\n%s", i, actual, expected)
+		}
+	}
+}
+
+func TestCheckHeaderPoW(test *testing.T) {
+	genesis := newTestHeaders(test)[0]
+	highHash := genesis
+	highHash.Nonce++
+	aboveLimit := genesis
+	aboveLimit.Bits = 0x1f0fffff
+	zeroTarget := genesis
+	zeroTarget.Bits = 0
+	data := []struct {
+		name     string
+		header   types.BlockHeader
+		expected error
+	}{
+		{"genesis", genesis, nil},
+		{"changed nonce", highHash, ErrHighHash},
+		{"target above limit", aboveLimit, ErrBadTarget},
+		{"zero target", zeroTarget, ErrBadTarget},
+	}
+	for _, d := range data {
+		actual := CheckHeaderPoW(d.header, &params.GroestlcoinParams)
+		if actual != d.expected {
+			test.Errorf("groestlcoin.TestCheckHeaderPoW, %s:\nactual:\n%v\nexpected:\n%v", d.name, actual, d.expected)
+		}
+	}
+}
+
+func TestCheckHeaderChain(test *testing.T) {
+	headers := newTestHeaders(test)
+	otherGenesis := params.GroestlcoinParams
+	otherGenesis.GenesisHash = bytes.Repeat([]byte{0}, types.HASH_SIZE)
+	data := []struct {
+		name     string
+		headers  []types.BlockHeader
+		p        *params.ChainParams
+		index    int
+		expected error
+	}{
+		{"valid", headers, &params.GroestlcoinParams, 3, nil},
+		{"without genesis", headers[1:], &params.GroestlcoinParams, 2, nil},
+		{"missing header", []types.BlockHeader{headers[0], headers[2]}, &params.GroestlcoinParams, 1, ErrNotConnected},
+		{"another network", headers, &otherGenesis, 0, ErrBadGenesis},
+	}
+	for _, d := range data {
+		index, err := CheckHeaderChain(d.headers, d.p)
+		if index != d.index || err != d.expected {
+			test.Errorf("groestlcoin.TestCheckHeaderChain, %s:\nactual:\n%d, %v\nexpected:\n%d, %v", d.name, index, err, d.index, d.expected)
+		}
+	}
+}
+
+func TestBestHeaderChain(test *testing.T) {
+	headers := newTestHeaders(test)
+	stale := headers[2]
+	stale.Nonce++
+	unordered := []types.BlockHeader{headers[2], stale, headers[0], headers[1]}
+	chain, staleCount := BestHeaderChain(unordered, &params.GroestlcoinParams)
+	if staleCount != 1 || len(chain) != len(headers) {
+		test.Fatalf("groestlcoin.TestBestHeaderChain:\nactual:\n%d headers, %d stale\nexpected:\n%d headers, 1 stale", len(chain), staleCount, len(headers))
+	}
+	for i := range headers {
+		if chain[i].Nonce != headers[i].Nonce {
+			test.Errorf("groestlcoin.TestBestHeaderChain[%d]:\nactual:\n%d\nexpected:\n%d", i, chain[i].Nonce, headers[i].Nonce)
+		}
+	}
+}
+
+func TestBestHeaderChain_MostWork(test *testing.T) {
+	headers := newTestHeaders(test)
+
+	// A single header with a much lower target outweighs the two
+	// headers of the longer chain.
+	heavy := headers[1]
+	heavy.Bits = 0x1d00ffff
+	chain, staleCount := BestHeaderChain(append(headers, heavy), &params.GroestlcoinParams)
+	if staleCount != 2 || len(chain) != 2 || chain[1].Bits != heavy.Bits {
+		test.Errorf("groestlcoin.TestBestHeaderChain_MostWork, heavier fork:\nactual:\n%d headers, %d stale\nexpected:\n2 headers, 2 stale", len(chain), staleCount)
+	}
+
+	// Of forks with equal work the tip stored first wins.
+	stale := headers[2]
+	stale.Nonce++
+	chain, _ = BestHeaderChain([]types.BlockHeader{headers[0], headers[1], stale, headers[2]}, &params.GroestlcoinParams)
+	if len(chain) != len(headers) || chain[2].Nonce != stale.Nonce {
+		test.Errorf("groestlcoin.TestBestHeaderChain_MostWork, equal work:\nactual:\n%d\nexpected:\n%d", chain[len(chain)-1].Nonce, stale.Nonce)
+	}
+}
@@ -29,6 +29,16 @@ type getblocks struct {
 	BestHeight int
 }
 
+type getheaders struct {
+	AddrFrom   string
+	BestHeight int
+}
+
+type headers struct {
+	AddrFrom string
+	Headers  [][]byte
+}
+
 type getdata struct {
 	AddrFrom string
 	Type     string
@@ -50,6 +60,7 @@ type version struct {
 	Version    int
 	BestHeight int
 	AddrFrom   string
+	Timestamp  int64
 }
 
 type ping struct {
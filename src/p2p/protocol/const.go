@@ -16,22 +16,31 @@
 package protocol
 
 const (
-	C_TX        = "tx"
-	C_INV       = "inv"
-	C_PING      = "ping"
-	C_PONG      = "pong"
-	C_ADDR      = "addr"
-	C_BLOCK     = "block"
-	C_ERROR     = "error"
-	C_VERSION   = "version"
-	C_GETDATA   = "getdata"
-	C_GETBLOCKS = "getblocks"
-	C_MESSAGE   = "msg"
-	C_SYNCED    = "synced"
+	C_TX         = "tx"
+	C_INV        = "inv"
+	C_PING       = "ping"
+	C_PONG       = "pong"
+	C_ADDR       = "addr"
+	C_BLOCK      = "block"
+	C_ERROR      = "error"
+	C_VERSION    = "version"
+	C_GETDATA    = "getdata"
+	C_GETBLOCKS  = "getblocks"
+	C_GETHEADERS = "getheaders"
+	C_HEADERS    = "headers"
+	C_MESSAGE    = "msg"
+	C_SYNCED     = "synced"
 )
 
 const (
 	PROTOCOL       = "tcp"
 	NODE_VERSION   = 1
 	COMMAND_LENGTH = 12
+
+	// MAGIC_LENGTH is the size of the network magic prefixing every message.
+	MAGIC_LENGTH = 4
+
+	// GETBLOCKS_TIMEOUT is the time in seconds after which an unanswered
+	// getblocks request to a peer may be sent again.
+	GETBLOCKS_TIMEOUT = 60
 )
@@ -23,8 +23,10 @@ import (
 	"fmt"
 	"log"
 	"sync/atomic"
+	"time"
 
 	"github.com/YuriyLisovskiy/blockchain-go/src/core"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
 	"github.com/YuriyLisovskiy/blockchain-go/src/p2p/static"
 	"github.com/YuriyLisovskiy/blockchain-go/src/utils"
@@ -57,17 +59,42 @@ func (p *Protocol) HandleBlock(request []byte) {
 		log.Panic(err)
 	}
 	blockData := payload.Block
-	block := core.DeserializeBlock(blockData)
 	utils.PrintLog("Received a new block!\n")
-	p.Config.Chain.AddBlock(block)
-	utils.PrintLog(fmt.Sprintf("Added block %x\n", block.Hash))
+
+	// Oversized blocks are rejected before they are decoded.
+	if len(blockData) > vars.MAX_BLOCK_SIZE {
+		err = core.ErrBlockTooLarge
+		utils.PrintLog(fmt.Sprintf("Rejected block of %d bytes: %s\n", len(blockData), err))
+	} else if block, decodeErr := core.DecodeBlock(blockData); decodeErr != nil {
+		utils.PrintLog(fmt.Sprintf("Dropped malformed block: %s\n", decodeErr))
+	} else {
+		err = p.Config.Chain.AddBlock(block)
+		if err != nil {
+			utils.PrintLog(fmt.Sprintf("Rejected block %x: %s\n", block.Hash, err))
+		} else {
+			utils.PrintLog(fmt.Sprintf("Added block %x\n", block.Hash))
+		}
+	}
 	if len(static.BlocksInTransit) > 0 {
 		blockHash := static.BlocksInTransit[0]
 		p.SendGetData(static.SelfNodeAddress, payload.AddrFrom, C_BLOCK, blockHash)
 		static.BlocksInTransit = static.BlocksInTransit[1:]
 	} else {
-		UTXOSet := core.UTXOSet{BlockChain: *p.Config.Chain}
-		UTXOSet.Reindex()
+
+		// The block's parent is unknown, so some blocks were missed; ask the
+		// peer for them unless a request to it is still outstanding.
+		if err == core.ErrPrevBlockNotFound {
+			sentAt, inFlight := static.GetBlocksInFlight[payload.AddrFrom]
+			if inFlight && time.Now().Unix()-sentAt < GETBLOCKS_TIMEOUT {
+				return
+			}
+			delete(static.GetBlocksInFlight, payload.AddrFrom)
+			if p.SendGetBlocks(static.SelfNodeAddress, payload.AddrFrom) {
+				static.GetBlocksInFlight[payload.AddrFrom] = time.Now().Unix()
+				atomic.StoreInt32(&vars.Syncing, 1)
+				return
+			}
+		}
 		atomic.StoreInt32(&vars.Syncing, 0)
 	}
 }
@@ -84,6 +111,13 @@ func (p *Protocol) HandleInv(request []byte) {
 	utils.PrintLog(fmt.Sprintf("Recevied inventory with %d %s\n", len(payload.Items), payload.Type))
 	switch payload.Type {
 	case C_BLOCK:
+		delete(static.GetBlocksInFlight, payload.AddrFrom)
+		if len(payload.Items) == 0 {
+			if len(static.BlocksInTransit) == 0 {
+				atomic.StoreInt32(&vars.Syncing, 0)
+			}
+			return
+		}
 		static.BlocksInTransit = payload.Items
 		blockHash := payload.Items[0]
 		var newInTransit [][]byte
@@ -112,10 +146,80 @@ func (p *Protocol) HandleGetBlocks(request []byte) {
 	if err != nil {
 		log.Panic(err)
 	}
-	blocks := p.Config.Chain.GetBlockHashes(payload.BestHeight)
+	chain := p.Config.Chain
+	blocks, err := chain.GetBlockHashesInRange(payload.BestHeight+1, chain.GetBestHeight())
+	if err != nil {
+		utils.PrintLog(fmt.Sprintf("Failed to read block hashes: %s\n", err))
+		return
+	}
 	p.SendInv(static.SelfNodeAddress, payload.AddrFrom, C_BLOCK, blocks)
 }
 
+func (p *Protocol) HandleGetHeaders(request []byte) {
+	var buff bytes.Buffer
+	payload := getheaders{}
+	buff.Write(request[COMMAND_LENGTH:])
+	dec := gob.NewDecoder(&buff)
+	err := dec.Decode(&payload)
+	if err != nil {
+		log.Panic(err)
+	}
+	chain := p.Config.Chain
+	hashes, err := chain.GetBlockHashesInRange(payload.BestHeight+1, chain.GetBestHeight())
+	if err != nil {
+		utils.PrintLog(fmt.Sprintf("Failed to read block hashes: %s\n", err))
+		return
+	}
+	var blockHeaders []types.BlockHeader
+	for _, hash := range hashes {
+		header, err := chain.GetBlockHeader(hash)
+		if err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to read block header: %s\n", err))
+			return
+		}
+		blockHeaders = append(blockHeaders, header)
+	}
+	p.SendHeaders(static.SelfNodeAddress, payload.AddrFrom, blockHeaders)
+}
+
+// HandleHeaders stores received headers in the block index, so they are
+// checked before any transactions are downloaded, then requests blocks
+// of the stored headers which have no data yet.
+func (p *Protocol) HandleHeaders(request []byte) {
+	var buff bytes.Buffer
+	payload := headers{}
+	buff.Write(request[COMMAND_LENGTH:])
+	dec := gob.NewDecoder(&buff)
+	err := dec.Decode(&payload)
+	if err != nil {
+		log.Panic(err)
+	}
+	chain := p.Config.Chain
+	var missing [][]byte
+	for _, data := range payload.Headers {
+		header, err := types.DeserializeBlockHeader(data)
+		if err != nil {
+			utils.PrintLog(fmt.Sprintf("Dropped malformed header: %s\n", err))
+			break
+		}
+		err = chain.AddBlockHeader(header)
+		if err != nil && err != core.ErrBlockExists {
+			utils.PrintLog(fmt.Sprintf("Rejected header %x: %s\n", header.Hash(), err))
+			break
+		}
+		entry, err := chain.GetBlockIndexEntry(header.Hash())
+		if err == nil && !entry.HaveData() {
+			missing = append(missing, entry.Hash)
+		}
+	}
+	if len(missing) == 0 {
+		atomic.StoreInt32(&vars.Syncing, 0)
+		return
+	}
+	static.BlocksInTransit = missing[1:]
+	p.SendGetData(static.SelfNodeAddress, payload.AddrFrom, C_BLOCK, missing[0])
+}
+
 func (p *Protocol) HandleGetData(request []byte) {
 	var buff bytes.Buffer
 	payload := getdata{}
@@ -151,16 +255,23 @@ func (p *Protocol) HandleTx(request []byte) {
 		log.Panic(err)
 	}
 	txData := payload.Transaction
-	tx := core.DeserializeTransaction(txData)
-	static.MemPool[hex.EncodeToString(tx.Hash)] = tx
+	tx, err := core.DecodeTransaction(txData)
+	if err != nil {
+		utils.PrintLog(fmt.Sprintf("Dropped malformed transaction: %s\n", err))
+		return
+	}
 
-	if !p.Config.Chain.VerifyTransaction(tx) {
-		utils.PrintLog(fmt.Sprintf("Invalid transaction %x\n", tx.Hash))
+	// Transactions which can not be included in the next block are not accepted.
+	err = p.Config.Chain.VerifyTransaction(tx)
+	if err != nil {
+		utils.PrintLog(fmt.Sprintf("Transaction %x is rejected: %s\n", tx.Hash, err))
 		data, err := json.MarshalIndent(tx, "", "  ")
 		if err == nil {
 			fmt.Println(string(data))
 		}
+		return
 	}
+	static.MemPool[hex.EncodeToString(tx.Hash)] = tx
 
 	/*
 		if selfNodeAddress == KnownNodes[0] {
@@ -204,7 +315,7 @@ func (p *Protocol) HandleTx(request []byte) {
 	*/
 }
 
-func (p *Protocol) HandleVersion(request []byte) {
+func (p *Protocol) HandleVersion(request []byte, host string) {
 	var buff bytes.Buffer
 	payload := version{}
 	buff.Write(request[COMMAND_LENGTH:])
@@ -213,11 +324,17 @@ func (p *Protocol) HandleVersion(request []byte) {
 	if err != nil {
 		log.Panic(err)
 	}
+
+	// Peers of older versions do not report their time. Samples are keyed
+	// by the host the message came from, not by the address a peer claims.
+	if payload.Timestamp != 0 {
+		core.AddTimeData(host, payload.Timestamp-time.Now().Unix())
+	}
 	myBestHeight := p.Config.Chain.GetBestHeight()
 	foreignerBestHeight := payload.BestHeight
 	if myBestHeight < foreignerBestHeight {
 		atomic.StoreInt32(&vars.Syncing, 1)
-		p.SendGetBlocks(static.SelfNodeAddress, payload.AddrFrom)
+		p.SendGetHeaders(static.SelfNodeAddress, payload.AddrFrom)
 	} else if myBestHeight > foreignerBestHeight {
 		p.SendVersion(static.SelfNodeAddress, payload.AddrFrom)
 	} else {
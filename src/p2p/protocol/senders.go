@@ -21,6 +21,7 @@ import (
 	"io"
 	"log"
 	"net"
+	"time"
 
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
 )
@@ -89,6 +90,24 @@ func (p *Protocol) SendGetBlocks(addrFrom, addrTo string) bool {
 	))
 }
 
+func (p *Protocol) SendGetHeaders(addrFrom, addrTo string) bool {
+	return p.sendData(addrTo, MakeRequest(
+		getheaders{
+			AddrFrom:   addrFrom,
+			BestHeight: p.Config.Chain.GetBestHeight(),
+		},
+		C_GETHEADERS,
+	))
+}
+
+func (p *Protocol) SendHeaders(addrFrom, addrTo string, blockHeaders []types.BlockHeader) bool {
+	payload := headers{AddrFrom: addrFrom}
+	for _, header := range blockHeaders {
+		payload.Headers = append(payload.Headers, header.Serialize())
+	}
+	return p.sendData(addrTo, MakeRequest(payload, C_HEADERS))
+}
+
 func (p *Protocol) SendGetData(addrFrom, addrTo, kind string, id []byte) bool {
 	return p.sendData(addrTo, MakeRequest(
 		getdata{
@@ -118,6 +137,7 @@ func (p *Protocol) SendVersion(addrFrom, addrTo string) bool {
 				Version:    NODE_VERSION,
 				BestHeight: p.Config.Chain.GetBestHeight(),
 				AddrFrom:   addrFrom,
+				Timestamp:  time.Now().Unix(),
 			},
 			C_VERSION,
 		),
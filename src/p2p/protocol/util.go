@@ -17,9 +17,12 @@ package protocol
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/gob"
 	"fmt"
 	"log"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
 )
 
 func ExtractCommand(request []byte) []byte {
@@ -54,6 +57,25 @@ func GobEncode(data interface{}) []byte {
 	return buff.Bytes()
 }
 
+// NetworkMagic returns the magic of the active network as it is written
+// at the beginning of messages.
+func NetworkMagic() []byte {
+	magic := make([]byte, MAGIC_LENGTH)
+	binary.LittleEndian.PutUint32(magic, params.Active().Net)
+	return magic
+}
+
+// MakeRequest creates a message of the active network with given command and payload.
 func MakeRequest(data interface{}, cmd string) []byte {
-	return append(CommandToBytes(cmd), GobEncode(data)...)
+	request := append(NetworkMagic(), CommandToBytes(cmd)...)
+	return append(request, GobEncode(data)...)
+}
+
+// StripNetworkMagic removes the network magic from the received message.
+// Messages which are too short or belong to another network are rejected.
+func StripNetworkMagic(request []byte) ([]byte, bool) {
+	if len(request) < MAGIC_LENGTH+COMMAND_LENGTH || !bytes.Equal(request[:MAGIC_LENGTH], NetworkMagic()) {
+		return nil, false
+	}
+	return request[MAGIC_LENGTH:], true
 }
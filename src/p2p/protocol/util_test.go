@@ -15,8 +15,37 @@
 
 package protocol
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
 
 func Test(test *testing.T) {
 
 }
+
+func TestStripNetworkMagic(test *testing.T) {
+	defer params.SetActive(params.Active())
+	params.SetActive(&params.TestNetParams)
+	request := MakeRequest(ping{AddrFrom: "localhost:3000"}, C_PING)
+	payload, ok := StripNetworkMagic(request)
+	if !ok || BytesToCommand(ExtractCommand(payload)) != C_PING {
+		test.Errorf("protocol.TestStripNetworkMagic, same network:\nactual:\n%v\nexpected:\n%v", ok, true)
+	}
+	for name, r := range map[string][]byte{"short": request[:MAGIC_LENGTH], "no magic": request[MAGIC_LENGTH:]} {
+		if _, ok := StripNetworkMagic(r); ok {
+			test.Errorf("protocol.TestStripNetworkMagic, %s:\nactual:\n%v\nexpected:\n%v", name, ok, false)
+		}
+	}
+
+	// Main network nodes do not accept test network messages.
+	params.SetActive(&params.MainNetParams)
+	if _, ok := StripNetworkMagic(request); ok {
+		test.Errorf("protocol.TestStripNetworkMagic, other network:\nactual:\n%v\nexpected:\n%v", ok, false)
+	}
+	if magic := []byte{0xb2, 0x6b, 0x7c, 0xfa}; !bytes.Equal(NetworkMagic(), magic) {
+		test.Errorf("protocol.TestStripNetworkMagic, magic:\nactual:\n%x\nexpected:\n%x", NetworkMagic(), magic)
+	}
+}
@@ -37,11 +37,29 @@ type Server struct {
 	miningService services.MiningService
 }
 
+// remoteHost returns the host of the connection's remote address without
+// the port, which differs for every connection a peer opens.
+func remoteHost(conn net.Conn) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+	return host
+}
+
 func handleConnection(conn net.Conn, proto *protocol.Protocol) {
 	request, err := ioutil.ReadAll(conn)
 	if err != nil {
 		log.Panic(err)
 	}
+
+	// Nodes of other networks are not talked to.
+	request, ok := protocol.StripNetworkMagic(request)
+	if !ok {
+		utils.PrintLog(fmt.Sprintf("Dropped a message from %s: wrong network\n", conn.RemoteAddr()))
+		conn.Close()
+		return
+	}
 	command := protocol.BytesToCommand(request[:protocol.COMMAND_LENGTH])
 	utils.PrintLog(fmt.Sprintf("Received %s command\n", command))
 	switch command {
@@ -53,12 +71,16 @@ func handleConnection(conn net.Conn, proto *protocol.Protocol) {
 		proto.HandleInv(request)
 	case protocol.C_GETBLOCKS:
 		proto.HandleGetBlocks(request)
+	case protocol.C_GETHEADERS:
+		proto.HandleGetHeaders(request)
+	case protocol.C_HEADERS:
+		proto.HandleHeaders(request)
 	case protocol.C_GETDATA:
 		proto.HandleGetData(request)
 	case protocol.C_TX:
 		proto.HandleTx(request)
 	case protocol.C_VERSION:
-		proto.HandleVersion(request)
+		proto.HandleVersion(request, remoteHost(conn))
 	case protocol.C_PING:
 		proto.HandlePing(request)
 	case protocol.C_PONG:
@@ -75,6 +97,7 @@ func handleConnection(conn net.Conn, proto *protocol.Protocol) {
 
 func (s *Server) Start(cfg config.Config, minerAddress string) {
 	static.SelfNodeAddress = fmt.Sprintf("%s:%d", cfg.Ip, cfg.Port)
+	static.AddSeedNodes()
 	if _, ok := static.KnownNodes[static.SelfNodeAddress]; ok {
 		delete(static.KnownNodes, static.SelfNodeAddress)
 	}
@@ -15,16 +15,27 @@
 
 package static
 
-import "github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+import (
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
+	"github.com/YuriyLisovskiy/blockchain-go/src/params"
+)
 
 var (
 	SelfNodeAddress string
 
-	KnownNodes = map[string]bool{
-		"localhost:3000": true,
-		"localhost:3001": true,
-	}
+	KnownNodes = make(map[string]bool)
 
 	BlocksInTransit [][]byte
 	MemPool         = make(map[string]types.Transaction)
+
+	// GetBlocksInFlight maps peers which were asked for blocks to the
+	// time of the request, until they answer with an inventory.
+	GetBlocksInFlight = make(map[string]int64)
 )
+
+// AddSeedNodes adds seed peers of the active network to known nodes.
+func AddSeedNodes() {
+	for _, seed := range params.Active().Seeds {
+		KnownNodes[seed] = true
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package params defines parameters of the networks a node can join.
+// Nodes of different networks use different genesis blocks, address
+// versions and message magic, so they can not accept each other's data.
+package params
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/consensus"
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
+)
+
+var ErrUnknownNetwork = errors.New("unknown network")
+
+// AddressChecksum selects the function computing checksums of base58 addresses.
+type AddressChecksum int
+
+const (
+
+	// SHA256D_CHECKSUM is the first 4 bytes of double SHA-256 of the payload.
+	SHA256D_CHECKSUM AddressChecksum = iota
+
+	// GROESTL_CHECKSUM is the first 4 bytes of double Groestl-512 of the payload.
+	GROESTL_CHECKSUM
+)
+
+// ChainParams holds consensus rules and network settings of a block chain.
+type ChainParams struct {
+
+	// Name identifies the network in the configuration.
+	Name string
+
+	// Net is the magic value which prefixes every message of the network.
+	// Values of other chains are not reused, so their nodes drop the messages.
+	Net uint32
+
+	DefaultPort int
+
+	// Seeds are peers a node connects to when it does not know others.
+	Seeds []string
+
+	// The genesis block is built from these fields, its hash is
+	// checked against GenesisHash.
+	GenesisMessage   string
+	GenesisTimestamp int64
+	GenesisBits      uint32
+	GenesisNonce     uint32
+	GenesisHash      []byte
+
+	// Version bytes and checksum of base58 encoded addresses.
+	PubKeyHashAddrID byte
+	ScriptHashAddrID byte
+	AddressChecksum  AddressChecksum
+
+	Subsidy consensus.SubsidyFunc
+
+	// PowHasher computes block hashes, which are checked against the target.
+	PowHasher consensus.PowHasher
+
+	// PowLimitBits is the compact form of the highest allowed target.
+	PowLimitBits uint32
+
+	// TargetSpacing is the expected time between blocks in seconds.
+	TargetSpacing int64
+
+	// DGWPastBlocks is the number of previous blocks used for retargeting.
+	DGWPastBlocks int
+
+	// PowNoRetargeting keeps the difficulty at the limit.
+	PowNoRetargeting bool
+
+	// MineBlocksOnDemand disables mining by nodes, blocks are created
+	// only with the generate command.
+	MineBlocksOnDemand bool
+}
+
+const genesisMessage = "Block Chain Go genesis block"
+
+var MainNetParams = ChainParams{
+	Name:        "mainnet",
+	Net:         0xfa7c6bb2,
+	DefaultPort: 8000,
+	Seeds: []string{
+		"localhost:3000",
+		"localhost:3001",
+	},
+	GenesisMessage:   genesisMessage,
+	GenesisTimestamp: 1536000000,
+	GenesisBits:      vars.POW_LIMIT_BITS,
+	GenesisNonce:     3776,
+	GenesisHash:      mustDecodeHex("0000f28ea994f92cef84f7be7f96c282552aa2de16dd4b75afce8de12c13f93f"),
+	PubKeyHashAddrID: 0x00,
+	ScriptHashAddrID: 0x05,
+	AddressChecksum:  SHA256D_CHECKSUM,
+	Subsidy:          consensus.HalvingSubsidy(vars.MINING_REWARD, vars.SUBSIDY_HALVING_INTERVAL),
+	PowHasher:        consensus.X11Hasher{},
+	PowLimitBits:     vars.POW_LIMIT_BITS,
+	TargetSpacing:    vars.TARGET_SPACING,
+	DGWPastBlocks:    vars.DGW_PAST_BLOCKS,
+}
+
+var TestNetParams = ChainParams{
+	Name:        "testnet",
+	Net:         0xfb7d6cb3,
+	DefaultPort: 18000,
+	Seeds: []string{
+		"localhost:13000",
+		"localhost:13001",
+	},
+	GenesisMessage:   genesisMessage,
+	GenesisTimestamp: 1536000001,
+	GenesisBits:      vars.POW_LIMIT_BITS,
+	GenesisNonce:     46154,
+	GenesisHash:      mustDecodeHex("0000f903a0ac4233c815d18ddb19aef59c0aedca7978d1eefd79031b5f792b07"),
+	PubKeyHashAddrID: 0x6f,
+	ScriptHashAddrID: 0xc4,
+	AddressChecksum:  SHA256D_CHECKSUM,
+	Subsidy:          consensus.HalvingSubsidy(vars.MINING_REWARD, vars.SUBSIDY_HALVING_INTERVAL),
+	PowHasher:        consensus.X11Hasher{},
+	PowLimitBits:     vars.POW_LIMIT_BITS,
+	TargetSpacing:    vars.TARGET_SPACING,
+	DGWPastBlocks:    vars.DGW_PAST_BLOCKS,
+}
+
+// RegTestParams describe a private network for testing, blocks are found
+// almost immediately when requested and the subsidy halves quickly.
+var RegTestParams = ChainParams{
+	Name:               "regtest",
+	Net:                0xfc7e6db4,
+	DefaultPort:        28000,
+	GenesisMessage:     genesisMessage,
+	GenesisTimestamp:   1536000002,
+	GenesisBits:        0x207fffff,
+	GenesisNonce:       0,
+	GenesisHash:        mustDecodeHex("4f09acdcf79f1b1fb68bec25619d87f90aa6f7e3203f10c1425faaeec8c88960"),
+	PubKeyHashAddrID:   0x6f,
+	ScriptHashAddrID:   0xc4,
+	AddressChecksum:    SHA256D_CHECKSUM,
+	Subsidy:            consensus.HalvingSubsidy(vars.MINING_REWARD, 150),
+	PowHasher:          consensus.X11Hasher{},
+	PowLimitBits:       0x207fffff,
+	TargetSpacing:      vars.TARGET_SPACING,
+	DGWPastBlocks:      vars.DGW_PAST_BLOCKS,
+	PowNoRetargeting:   true,
+	MineBlocksOnDemand: true,
+}
+
+// GroestlcoinParams describe the Groestlcoin main network. They allow to
+// exchange addresses with Groestlcoin tools and to read its chain data.
+// Blocks of Groestlcoin have another format, so the node can not join
+// the network and the parameters are not selectable by name. Amounts are
+// in units of this chain, so the subsidy is rounded to 10^-6 coin.
+var GroestlcoinParams = ChainParams{
+	Name:             "groestlcoin",
+	Net:              0xd4b4bef9,
+	DefaultPort:      1331,
+	GenesisTimestamp: 1395342829,
+	GenesisBits:      0x1e0fffff,
+	GenesisNonce:     220035,
+
+	// Groestlcoin displays the hash in reversed byte order as
+	// 00000ac5927c594d49cc0bdb81759d0da8297eb614683d3acb62f0703b639023.
+	GenesisHash:      mustDecodeHex("2390633b70f062cb3a3d6814b67e29a80d9d7581db0bcc494d597c92c50a0000"),
+	PubKeyHashAddrID: 36,
+	ScriptHashAddrID: 5,
+	AddressChecksum:  GROESTL_CHECKSUM,
+	Subsidy:          consensus.GroestlcoinSubsidy(vars.COIN),
+	PowHasher:        consensus.GroestlHasher{},
+	PowLimitBits:     0x1e0fffff,
+	TargetSpacing:    60,
+	DGWPastBlocks:    24,
+}
+
+var networks = []*ChainParams{&MainNetParams, &TestNetParams, &RegTestParams}
+
+var active = &MainNetParams
+
+// Active returns parameters of the network the node works with.
+func Active() *ChainParams {
+	return active
+}
+
+// SetActive selects the network the node works with.
+func SetActive(p *ChainParams) {
+	active = p
+}
+
+// ByName returns parameters of the network with given name,
+// an empty name selects the main network.
+func ByName(name string) (*ChainParams, error) {
+	if name == "" {
+		return &MainNetParams, nil
+	}
+	for _, p := range networks {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, ErrUnknownNetwork
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018 Yuriy Lisovskiy
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"testing"
+
+	"github.com/YuriyLisovskiy/blockchain-go/src/core/types/money"
+)
+
+func TestByName(test *testing.T) {
+	data := []struct {
+		name     string
+		expected *ChainParams
+		err      error
+	}{
+		{"", &MainNetParams, nil},
+		{"mainnet", &MainNetParams, nil},
+		{"testnet", &TestNetParams, nil},
+		{"regtest", &RegTestParams, nil},
+		{"unknown", nil, ErrUnknownNetwork},
+	}
+	for _, d := range data {
+		actual, err := ByName(d.name)
+		if actual != d.expected || err != d.err {
+			test.Errorf("params.TestByName, %s:\nactual:\n%v, %v\nexpected:\n%v, %v", d.name, actual, err, d.expected, d.err)
+		}
+	}
+}
+
+func TestNetworks_Distinct(test *testing.T) {
+	foreign := map[uint32]string{
+		0xd9b4bef9:            "bitcoin mainnet",
+		0x0709110b:            "bitcoin testnet",
+		0xdab5bffa:            "bitcoin regtest",
+		GroestlcoinParams.Net: "groestlcoin",
+	}
+	for i, a := range networks {
+		if name, ok := foreign[a.Net]; ok {
+			test.Errorf("params.TestNetworks_Distinct: %s shares magic %x with %s", a.Name, a.Net, name)
+		}
+		for _, b := range networks[i+1:] {
+			if a.Net == b.Net {
+				test.Errorf("params.TestNetworks_Distinct: %s and %s share magic %x", a.Name, b.Name, a.Net)
+			}
+			if a.DefaultPort == b.DefaultPort {
+				test.Errorf("params.TestNetworks_Distinct: %s and %s share port %d", a.Name, b.Name, a.DefaultPort)
+			}
+		}
+	}
+}
+
+func TestSubsidy_InRange(test *testing.T) {
+	all := append([]*ChainParams{&GroestlcoinParams}, networks...)
+	for _, p := range all {
+		for _, height := range []int{0, 1, 2, 10080, 120000, 150000, 210000, 10000000} {
+			if subsidy := money.Amount(p.Subsidy(height)); !money.InRange(subsidy) {
+				test.Errorf("params.TestSubsidy_InRange, %s at %d:\nactual:\n%v\nexpected:\n%v", p.Name, height, subsidy, "amount in range")
+			}
+		}
+	}
+}
@@ -16,12 +16,13 @@
 package services
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"sync/atomic"
+	"time"
 
-	"github.com/YuriyLisovskiy/blockchain-go/src/core"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/types"
 	"github.com/YuriyLisovskiy/blockchain-go/src/core/vars"
 	"github.com/YuriyLisovskiy/blockchain-go/src/p2p/protocol"
@@ -38,14 +39,15 @@ func (ms *MiningService) Start(proto *protocol.Protocol, memPool *map[string]typ
 			if atomic.LoadInt32(&vars.Syncing) == 0 {
 				var txs []types.Transaction
 				for _, tx := range *memPool {
-					if proto.Config.Chain.VerifyTransaction(tx) {
+					err := proto.Config.Chain.VerifyTransaction(tx)
+					if err == nil {
 
 						txs = append(txs, tx)
 
 					} else {
 						// TODO: send an error to transaction's author
 
-						utils.PrintLog(fmt.Sprintf("Invalid transaction %x\n", tx.Hash))
+						utils.PrintLog(fmt.Sprintf("Invalid transaction %x: %s\n", tx.Hash, err))
 
 						data, err := json.MarshalIndent(tx, "", "  ")
 						if err == nil {
@@ -54,7 +56,10 @@ func (ms *MiningService) Start(proto *protocol.Protocol, memPool *map[string]typ
 					}
 					delete(*memPool, hex.EncodeToString(tx.Hash))
 				}
-				newBlock, err := proto.Config.Chain.MineBlock(ms.MinerAddress, txs)
+				ctx, cancel := context.WithCancel(context.Background())
+				go cancelOnSync(ctx, cancel)
+				newBlock, err := proto.Config.Chain.MineBlock(ctx, ms.MinerAddress, txs)
+				cancel()
 				if err == nil {
 					utils.PrintLog("New block is mined!\n")
 					go func() {
@@ -64,11 +69,26 @@ func (ms *MiningService) Start(proto *protocol.Protocol, memPool *map[string]typ
 							}
 						}
 					}()
-					UTXOSet := core.UTXOSet{BlockChain: *proto.Config.Chain}
-					//	UTXOSet.Reindex()
-					UTXOSet.Update(newBlock)
 				}
 			}
 		}
 	}()
 }
+
+// cancelOnSync stops mining when the node starts to download blocks from
+// peers, a block mined meanwhile would not extend the new tip.
+func cancelOnSync(ctx context.Context, cancel context.CancelFunc) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if atomic.LoadInt32(&vars.Syncing) == 1 {
+				cancel()
+				return
+			}
+		}
+	}
+}
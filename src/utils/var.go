@@ -19,5 +19,10 @@ var (
 	DBFile = "BlockChain_%d.db"
 	WalletFile = "wallets_%d.dat"
 	BLOCKS_BUCKET = []byte("blocks")
+	BLOCK_INDEX_BUCKET = []byte("blockindex")
+	HEIGHT_INDEX_BUCKET = []byte("heightindex")
+	TX_INDEX_BUCKET = []byte("txindex")
+	ADDR_INDEX_BUCKET = []byte("addrindex")
 	LAST_BLOCK_HASH = []byte("l")
+	DB_VERSION = []byte("version")
 )